@@ -3,6 +3,7 @@ package main
 import (
 	"log"
 	"os"
+	"strings"
 
 	"github.com/rizkirmdhnnn/goodreadscrape/internal/app"
 	"github.com/rizkirmdhnnn/goodreadscrape/internal/config"
@@ -20,8 +21,8 @@ func main() {
 	// Initialize logger
 	log.SetFlags(log.LstdFlags | log.Lshortfile)
 
-	log.Printf("Starting GoodScrape with config: APIKey=%s, Concurrency=%d, MaxReviews=%d, Language=%s, OutputFile=%s",
-		cfg.APIKey, cfg.Concurrency, cfg.MaxReviews, cfg.Language, cfg.OutputFile)
+	log.Printf("Starting GoodScrape with config: APIKey=%s, Concurrency=%d, MaxReviews=%d, Language=%s, OutputFiles=%s",
+		cfg.APIKey, cfg.Concurrency, cfg.MaxReviews, cfg.Language, strings.Join(cfg.OutputFiles, ","))
 
 	// Initialize and run the application
 	application := app.NewScraperApp(cfg)
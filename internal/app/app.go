@@ -6,11 +6,20 @@ import (
 	"log"
 	"os"
 	"os/signal"
+	"path/filepath"
+	"sort"
+	"strings"
 	"sync"
 	"syscall"
+	"time"
+	"unicode"
 
 	"github.com/rizkirmdhnnn/goodreadscrape/internal/config"
+	"github.com/rizkirmdhnnn/goodreadscrape/internal/filter"
+	"github.com/rizkirmdhnnn/goodreadscrape/internal/journal"
+	"github.com/rizkirmdhnnn/goodreadscrape/internal/langdetect"
 	"github.com/rizkirmdhnnn/goodreadscrape/internal/models"
+	"github.com/rizkirmdhnnn/goodreadscrape/internal/ratelimit"
 	"github.com/rizkirmdhnnn/goodreadscrape/internal/scraper"
 	"github.com/rizkirmdhnnn/goodreadscrape/internal/storage"
 	"github.com/rizkirmdhnnn/goodreadscrape/internal/validator"
@@ -18,18 +27,68 @@ import (
 
 // ScraperApp holds the application dependencies
 type ScraperApp struct {
-	Config    *config.Config
-	Scraper   scraper.GoodreadsScraper
-	Storage   storage.Storage
-	saveMutex sync.Mutex
+	Config         *config.Config
+	Scraper        scraper.GoodreadsScraper
+	Outputs        []Output
+	Journal        *journal.Journal
+	Known          models.KnownReviews // Reviews saved by earlier runs, for incremental runs
+	Filter         *filter.Pipeline    // Client-side review filters; nil keeps every review
+	PageLimiter    *ratelimit.Limiter
+	GraphQLLimiter *ratelimit.Limiter
+	saveMutex      sync.Mutex
+	saved          models.KnownReviews // Reviews saved by this run
+}
+
+// scrapeResult carries the outcome of scraping a single URL from a worker
+type scrapeResult struct {
+	URL       string
+	Job       job
+	BookData  models.BookData
+	Fetched   int  // Reviews fetched, before client-side filters
+	BookSaved bool // The book's metadata reached at least one output
+	Err       error
 }
 
 // NewScraperApp creates a new ScraperApp instance
 func NewScraperApp(cfg *config.Config) *ScraperApp {
+	// Limiters are shared by all workers so the configured rates apply to the whole run
+	pageLimiter := ratelimit.NewLimiter(cfg.PageRPS, cfg.PageBurst)
+	graphqlLimiter := ratelimit.NewLimiter(cfg.GraphQLRPS, cfg.GraphQLBurst)
+
+	// Formats are validated by Config.Validate, so this only fails on programmer error
+	outputs, err := newOutputs(cfg)
+	if err != nil {
+		log.Fatalf("Invalid output: %v", err)
+	}
+
+	// Filters are validated by Config.Validate too
+	filters, err := cfg.ReviewFilterConfig()
+	if err != nil {
+		log.Fatalf("Invalid review filters: %v", err)
+	}
+	pipeline, err := filters.Pipeline()
+	if err != nil {
+		log.Fatalf("Invalid review filters: %v", err)
+	}
+
 	return &ScraperApp{
-		Config:  cfg,
-		Scraper: scraper.NewGoodreadsScraper(cfg.APIKey, cfg.Verbose),
-		Storage: storage.NewCSVStorage(),
+		Config:         cfg,
+		PageLimiter:    pageLimiter,
+		GraphQLLimiter: graphqlLimiter,
+		Scraper: scraper.NewGoodreadsScraper(cfg.APIKey, cfg.Verbose,
+			scraper.WithBaseURL(cfg.BaseURL),
+			scraper.WithGraphQLURL(cfg.GraphQLURL),
+			scraper.WithRetryPolicy(scraper.RetryPolicy{
+				MaxAttempts: cfg.RetryAttempts,
+				BaseDelay:   cfg.RetryBaseDelay,
+				MaxDelay:    cfg.RetryMaxDelay,
+				Jitter:      cfg.RetryJitter,
+			}),
+			scraper.WithPageLimiter(pageLimiter),
+			scraper.WithGraphQLLimiter(graphqlLimiter),
+		),
+		Outputs: outputs,
+		Filter:  pipeline,
 	}
 }
 
@@ -38,6 +97,15 @@ func (app *ScraperApp) Run() {
 	var urls []string
 	var err error
 
+	// A resumed run continues its journal, and the URLs it was started with
+	if app.Config.Resume != "" {
+		app.Journal, err = journal.Open(app.Config.Resume)
+		if err != nil {
+			log.Fatalf("Failed to open journal: %v", err)
+		}
+		defer app.Journal.Close()
+	}
+
 	// Determine source of URLs
 	if app.Config.InputURL != "" {
 		urls = []string{app.Config.InputURL}
@@ -48,6 +116,9 @@ func (app *ScraperApp) Run() {
 			log.Fatalf("Failed to load URLs from file: %v", err)
 		}
 		log.Printf("Loaded %d URLs from %s", len(urls), app.Config.InputFile)
+	} else if app.Journal != nil {
+		urls = app.Journal.Run().URLs
+		log.Printf("Resuming %d URLs from %s", len(urls), app.Config.Resume)
 	} else {
 		log.Fatal("Error: You must provide either a single URL as an argument or an input file with -f")
 	}
@@ -55,7 +126,7 @@ func (app *ScraperApp) Run() {
 	// Validate URLs
 	var validURLs []string
 	for _, url := range urls {
-		if validator.ValidateGoodreadsURL(url) {
+		if validator.ValidateGoodreadsURL(url, app.Config.BaseURL) {
 			validURLs = append(validURLs, url)
 		} else {
 			log.Printf("Warning: Invalid Goodreads URL: %s", url)
@@ -67,15 +138,63 @@ func (app *ScraperApp) Run() {
 		return
 	}
 
-	log.Printf("Processing %d valid URLs with %d workers...", len(validURLs), app.Config.Concurrency)
+	if app.Journal == nil {
+		app.Journal, err = journal.Create(app.Config.JournalFile, journal.Run{
+			URLs:        validURLs,
+			OutputFiles: app.Config.OutputFiles,
+			Format:      app.Config.Format,
+			Partition:   app.Config.Partition,
+			Incremental: app.Config.Incremental,
+			StateFile:   app.Config.StateFile,
+			Filters: &journal.Filters{
+				Language:    app.Config.Language,
+				PerLanguage: app.Config.MaxPerLanguage,
+				Sort:        app.Config.Sort,
+				Stars:       app.Config.Stars,
+				TextOnly:    app.Config.TextOnly,
+				Search:      app.Config.Search,
+			},
+			StartedAt: time.Now().UTC(),
+		})
+		if err != nil {
+			log.Fatalf("Failed to create journal: %v", err)
+		}
+		defer app.Journal.Close()
+	}
+
+	// Skip books an earlier run completed; others continue where they stopped
+	var pending []job
+	skippedCount := 0
+	for _, url := range validURLs {
+		saved, _ := app.Journal.Entry(url)
+		if saved.Status == models.StatusComplete {
+			skippedCount++
+			continue
+		}
+		pending = append(pending, job{URL: url, Saved: saved})
+	}
+	if skippedCount > 0 {
+		log.Printf("⏭️ Skipping %d books completed by an earlier run", skippedCount)
+	}
+
+	// Incremental runs fetch reviews until they reach one saved before
+	if app.Config.Incremental {
+		app.Known, err = app.loadKnownReviews()
+		if err != nil {
+			log.Fatalf("Failed to read saved reviews: %v", err)
+		}
+		log.Printf("🔎 Incremental run: %d reviews of %d books were saved before", countReviews(app.Known), len(app.Known))
+	}
+
+	log.Printf("Processing %d valid URLs with %d workers...", len(pending), app.Config.Concurrency)
 
 	// Create context that cancels on interrupt signal
 	ctx, cancel := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
 	defer cancel()
 
 	// Start worker pool
-	jobs := make(chan string, len(validURLs))
-	results := make(chan models.BookData, len(validURLs))
+	jobs := make(chan job, len(pending))
+	results := make(chan scrapeResult, len(pending))
 	var wg sync.WaitGroup
 
 	// Start workers
@@ -87,9 +206,9 @@ func (app *ScraperApp) Run() {
 	// Send jobs
 	go func() {
 		defer close(jobs)
-		for _, url := range validURLs {
+		for _, j := range pending {
 			select {
-			case jobs <- url:
+			case jobs <- j:
 			case <-ctx.Done():
 				log.Println("Signal received. Stopping new job dispatch...")
 				return
@@ -106,40 +225,167 @@ func (app *ScraperApp) Run() {
 	// Process results
 	successCount := 0
 	processedCount := 0
-	totalURLs := len(validURLs)
+	completedCount := 0
+	totalURLs := len(pending)
+	failures := make(map[string]int)
+	outputFailures := make(map[string]int)
+	mislabelledCount := 0
+
+	// reportSaveErrors logs every failed output and reports whether any output succeeded
+	reportSaveErrors := func(errs []outputError, what string, title string) bool {
+		for _, e := range errs {
+			outputFailures[e.Path]++
+			log.Printf("❌ [%d/%d] Failed to save %s for %s to %s: %v", processedCount, totalURLs, what, title, e.Path, e.Err)
+		}
+		return len(errs) < len(app.Outputs)
+	}
 
-	for bookData := range results {
+	for result := range results {
 		processedCount++
+		bookData := result.BookData
+		title := bookData.Metadata.Title
+
+		// Client-side filters run before anything is saved
+		fetched := len(bookData.Reviews)
+		result.Fetched = fetched
+		bookData.ReviewsFetched = result.Job.Saved.Fetched() + fetched // Across runs resuming the book
+		if app.Filter != nil {
+			bookData.Reviews = app.Filter.Apply(bookData.Reviews)
+		}
+		mislabelledCount += mislabelled(bookData.Reviews)
+
+		// Record every book whose metadata was scraped, even with zero or partial
+		// reviews. A resumed book already has its row in appended books files,
+		// so those only remember it; others rewrite it with the updated count.
+		if bookData.Metadata.URL != "" {
+			resumed := result.Job.Saved.BookSaved
+			errs := app.saveAll(func(o Output) error {
+				if rememberer, ok := o.Storage.(storage.BookRememberer); ok && resumed {
+					rememberer.RememberBook(bookData, o.BooksPath())
+					return nil
+				}
+				return o.Storage.SaveBookData(bookData, o.BooksPath())
+			})
+			result.BookSaved = reportSaveErrors(errs, "book data", title)
+		}
 
-		if len(bookData.Reviews) > 0 {
-			app.saveMutex.Lock()
-			err := app.Storage.SaveReviews(bookData.Reviews, app.Config.OutputFile)
-			app.saveMutex.Unlock()
+		saved := false
+		if result.Err != nil {
+			category := scraper.ErrorCategory(result.Err)
+			failures[category]++
+			log.Printf("❌ [%d/%d] Failed to scrape %s (%s): %v", processedCount, totalURLs, result.URL, category, result.Err)
 
-			if err != nil {
-				log.Printf("❌ [%d/%d] Failed to save reviews for %s: %v", processedCount, totalURLs, bookData.Metadata.Title, err)
-			} else {
-				log.Printf("✅ [%d/%d] Saved %d reviews for '%s'", processedCount, totalURLs, len(bookData.Reviews), bookData.Metadata.Title)
+			// Save partial reviews fetched before the failure
+			if len(bookData.Reviews) > 0 {
+				errs := app.saveAll(func(o Output) error {
+					return o.Storage.SaveReviews(bookData.Reviews, o.Path)
+				})
+				if reportSaveErrors(errs, "partial reviews", title) {
+					log.Printf("⚠️ [%d/%d] Saved %d partial reviews for '%s'", processedCount, totalURLs, len(bookData.Reviews), title)
+					saved = true
+					app.rememberSaved(bookData.Reviews)
+				}
+			}
+		} else if len(bookData.Reviews) > 0 {
+			errs := app.saveAll(func(o Output) error {
+				return o.Storage.SaveReviews(bookData.Reviews, o.Path)
+			})
+			// A book counts as saved if at least one output holds its reviews
+			if reportSaveErrors(errs, "reviews", title) {
+				log.Printf("✅ [%d/%d] Saved %d reviews for '%s'", processedCount, totalURLs, len(bookData.Reviews), title)
 				successCount++
+				saved = true
+				app.rememberSaved(bookData.Reviews)
 			}
+		} else if fetched > 0 {
+			log.Printf("✅ [%d/%d] All %d reviews of '%s' were filtered out", processedCount, totalURLs, fetched, title)
+			successCount++
+		} else if app.Config.Incremental {
+			log.Printf("✅ [%d/%d] No new reviews for '%s'", processedCount, totalURLs, title)
+			successCount++
 		} else {
-			log.Printf("⚠️ [%d/%d] No reviews found for '%s'", processedCount, totalURLs, bookData.Metadata.Title)
+			log.Printf("⚠️ [%d/%d] No reviews found for '%s'", processedCount, totalURLs, title)
 			successCount++ // Count as success even if no reviews? Yes, scraping succeeded.
 		}
+
+		// Reviews that were all filtered out are done with, like saved ones
+		if fetched > 0 && len(bookData.Reviews) == 0 {
+			saved = true
+		}
+
+		// Record progress so that an interrupted run can be resumed
+		result.BookData = bookData
+		if app.finishJob(result, saved) == models.StatusComplete {
+			completedCount++
+		}
+	}
+
+	// Backends holding open handles must be flushed and closed before the files are usable
+	for _, e := range app.closeOutputs() {
+		outputFailures[e.Path]++
+		log.Printf("❌ Failed to close output %s: %v", e.Path, e.Err)
 	}
 
 	fmt.Println("---------------------------------------------------------")
 	log.Printf("🎉 Scraping completed! Successfully processed %d/%d URLs.", successCount, totalURLs)
-	log.Printf("📂 Results saved to: %s", app.Config.OutputFile)
+	if skippedCount > 0 {
+		log.Printf("⏭️ Skipped %d books completed by an earlier run", skippedCount)
+	}
+	if app.Config.Incremental {
+		log.Printf("🔎 Saved %d new reviews", countReviews(app.saved))
+	}
+	if mislabelledCount > 0 {
+		log.Printf("🌍 %d reviews look mislabelled: detected in another language than they were fetched under", mislabelledCount)
+	}
+	if app.Filter != nil && app.Filter.Len() > 0 {
+		removed := 0
+		for _, count := range app.Filter.Removed() {
+			removed += count.Removed
+		}
+		log.Printf("🧹 Filters kept %d of %d reviews", app.Filter.Seen()-removed, app.Filter.Seen())
+		for _, count := range app.Filter.Removed() {
+			log.Printf("   🧹 %s: removed %d", count.Name, count.Removed)
+		}
+	}
+	if retries := app.Scraper.RetryCount(); retries > 0 {
+		log.Printf("🔁 Retried %d requests", retries)
+	}
+	if len(failures) > 0 {
+		categories := make([]string, 0, len(failures))
+		for category := range failures {
+			categories = append(categories, category)
+		}
+		sort.Strings(categories)
+		for _, category := range categories {
+			log.Printf("   ❌ %s: %d", category, failures[category])
+		}
+	}
+	for _, output := range app.Outputs {
+		path := output.Path
+		if output.Partitioned {
+			path = storage.PartitionDir(output.Path) + string(filepath.Separator)
+		}
+		if n := outputFailures[output.Path]; n > 0 {
+			log.Printf("📂 Results saved to: %s (%s, ❌ %d failed writes)", path, output.Format, n)
+		} else {
+			log.Printf("📂 Results saved to: %s (%s)", path, output.Format)
+		}
+		if books := output.BooksPath(); books != output.Path {
+			log.Printf("📚 Book metadata saved to: %s", books)
+		}
+	}
+	if unfinished := totalURLs - completedCount; unfinished > 0 {
+		log.Printf("⏯️ %d books are unfinished; continue them with: -resume %s", unfinished, app.Config.JournalFile)
+	}
 	fmt.Println("---------------------------------------------------------")
 }
 
-func (app *ScraperApp) worker(ctx context.Context, id int, jobs <-chan string, results chan<- models.BookData, wg *sync.WaitGroup) {
+func (app *ScraperApp) worker(ctx context.Context, id int, jobs <-chan job, results chan<- scrapeResult, wg *sync.WaitGroup) {
 	defer wg.Done()
 
-	filters := models.Filters{Language: app.Config.Language}
+	for j := range jobs {
+		url := j.URL
 
-	for url := range jobs {
 		// Check context before starting work (optional, as channel close handles it, but good for fast exit)
 		select {
 		case <-ctx.Done():
@@ -152,10 +398,22 @@ func (app *ScraperApp) worker(ctx context.Context, id int, jobs <-chan string, r
 			log.Printf("Worker %d: Starting scraping for %s", id, url)
 		}
 
-		// Scrape book data
-		bookData, err := app.Scraper.ScrapeBookData(url, app.Config.MaxReviews, filters)
+		// Scrape book data, continuing after the reviews an earlier run saved
+		app.startJob(j)
+		filters := app.reviewFilters()
+		filters.After = j.Saved.NextPageToken
+		maxReviews := app.Config.MaxReviews - j.Saved.Fetched()
+		if filters.MaxPerLanguage && len(filters.Languages) > 1 {
+			// The page token counts the reviews of the language being fetched
+			maxReviews = app.Config.MaxReviews
+		}
+		bookData, err := app.Scraper.ScrapeBookData(ctx, url, maxReviews, filters)
 		if err != nil {
-			log.Printf("❌ Worker %d: Failed to scrape %s: %v", id, url, err)
+			if app.Config.Verbose {
+				log.Printf("Worker %d: Failed to scrape %s: %v", id, url, err)
+			}
+			// Failures are reported by Run, along with any partial reviews
+			results <- scrapeResult{URL: url, Job: j, BookData: bookData, Err: err}
 			continue
 		}
 
@@ -164,6 +422,67 @@ func (app *ScraperApp) worker(ctx context.Context, id int, jobs <-chan string, r
 			log.Printf("Worker %d: Finished scraping %s (%d reviews)", id, url, len(bookData.Reviews))
 		}
 
-		results <- bookData
+		results <- scrapeResult{URL: url, Job: j, BookData: bookData}
+	}
+}
+
+// mislabelledConfidence is the detection confidence needed to call a review
+// mislabelled
+const mislabelledConfidence = 0.9
+
+// Short reviews are often detected wrongly, even confidently ("5 stars" looks
+// English), so a review needs this many words or letters to be mislabelled
+const (
+	mislabelledMinWords   = 4
+	mislabelledMinLetters = 20
+)
+
+// mislabelled counts the reviews confidently detected in another language
+// than the one they were fetched under. Reviews fetched in a language the
+// detector cannot identify are left out, since they would always differ.
+func mislabelled(reviews []models.Review) int {
+	count := 0
+	for _, review := range reviews {
+		if review.FetchedLanguage != "" && review.Language != "" &&
+			langdetect.Supports(review.FetchedLanguage) &&
+			review.LanguageConfidence >= mislabelledConfidence &&
+			longEnough(review.ReviewText) &&
+			!langdetect.Matches(review.FetchedLanguage, review.Language) {
+			count++
+		}
+	}
+	return count
+}
+
+// longEnough reports whether text is long enough for its detected language
+// to be trusted
+func longEnough(text string) bool {
+	if len(strings.Fields(text)) >= mislabelledMinWords {
+		return true
+	}
+	letters := 0
+	for _, r := range text {
+		if unicode.IsLetter(r) {
+			letters++
+		}
+	}
+	return letters >= mislabelledMinLetters
+}
+
+// reviewFilters returns the review filters of the run. The configuration was
+// validated, so the values parse.
+func (app *ScraperApp) reviewFilters() models.Filters {
+	order, _ := scraper.ParseSort(app.Config.Sort)
+	minRating, maxRating, _ := scraper.ParseStars(app.Config.Stars)
+	languages, _ := scraper.ParseLanguages(app.Config.Language)
+	return models.Filters{
+		Languages:      languages,
+		MaxPerLanguage: app.Config.MaxPerLanguage,
+		Sort:           order,
+		MinRating:      minRating,
+		MaxRating:      maxRating,
+		TextOnly:       app.Config.TextOnly,
+		Search:         app.Config.Search,
+		Known:          app.Known,
 	}
 }
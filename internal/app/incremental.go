@@ -0,0 +1,61 @@
+package app
+
+import (
+	"log"
+
+	"github.com/rizkirmdhnnn/goodreadscrape/internal/models"
+	"github.com/rizkirmdhnnn/goodreadscrape/internal/storage"
+)
+
+// loadKnownReviews reads the reviews saved by earlier runs, from the state
+// file if one is configured and otherwise from every output that can read
+// them back
+func (app *ScraperApp) loadKnownReviews() (models.KnownReviews, error) {
+	known := models.KnownReviews{}
+	if app.Config.StateFile != "" {
+		return known, storage.ReadStateFile(app.Config.StateFile, known)
+	}
+
+	for _, output := range app.Outputs {
+		reader, ok := output.Storage.(storage.KnownReviewsReader)
+		if !ok {
+			continue
+		}
+		if err := reader.ReadKnownReviews(output.Path, known); err != nil {
+			return nil, err
+		}
+	}
+	return known, nil
+}
+
+// rememberSaved records reviews this run saved. With a state file, the file
+// is rewritten so that the next incremental run starts after them, even if
+// this one is interrupted.
+func (app *ScraperApp) rememberSaved(reviews []models.Review) {
+	if !app.Config.Incremental {
+		return
+	}
+	if app.saved == nil {
+		app.saved = models.KnownReviews{}
+	}
+	for _, review := range reviews {
+		app.saved.Add(review.WorkID, review.ReviewID, review.FetchedLanguage, review.CreatedAt)
+	}
+
+	if app.Config.StateFile == "" {
+		return
+	}
+	// Known is shared with the workers, so this run's reviews are kept apart
+	if err := storage.WriteStateFile(app.Config.StateFile, app.Known, app.saved); err != nil {
+		log.Printf("⚠️ Failed to update state file %s: %v", app.Config.StateFile, err)
+	}
+}
+
+// countReviews returns the number of reviews in known
+func countReviews(known models.KnownReviews) int {
+	n := 0
+	for _, work := range known {
+		n += len(work.ReviewIDs)
+	}
+	return n
+}
@@ -0,0 +1,57 @@
+package app
+
+import (
+	"path/filepath"
+	"testing"
+
+	"github.com/rizkirmdhnnn/goodreadscrape/internal/config"
+	"github.com/rizkirmdhnnn/goodreadscrape/internal/models"
+	"github.com/rizkirmdhnnn/goodreadscrape/internal/storage"
+)
+
+func TestRun_IncrementalWithStateFile(t *testing.T) {
+	dir := t.TempDir()
+	url := "https://www.goodreads.com/book/show/1"
+	stateFile := filepath.Join(dir, "state.json")
+	store := &fakeStorage{}
+	scraper := &fakeScraper{afters: make(map[string][]string)}
+
+	for i, name := range []string{"week1", "week2"} {
+		cfg := &config.Config{
+			InputURL:    url,
+			Concurrency: 1,
+			MaxReviews:  10,
+			OutputFiles: []string{filepath.Join(dir, name+".jsonl")},
+			JournalFile: filepath.Join(dir, name+".journal.jsonl"),
+			Incremental: true,
+			StateFile:   stateFile,
+		}
+		outputs, err := newOutputs(cfg)
+		if err != nil {
+			t.Fatal(err)
+		}
+		outputs[0].Storage = store
+
+		app := &ScraperApp{Config: cfg, Scraper: scraper, Outputs: outputs}
+		app.Run()
+
+		if i == 0 && countReviews(app.Known) != 0 {
+			t.Errorf("Expected nothing known before the first run, got %d reviews", countReviews(app.Known))
+		}
+		if i == 1 && countReviews(app.Known) != 2 {
+			t.Errorf("Expected the 2 reviews of the first run to be known, got %d", countReviews(app.Known))
+		}
+	}
+
+	if store.saved != 2 {
+		t.Errorf("Expected 2 reviews saved in total, got %d", store.saved)
+	}
+
+	known := models.KnownReviews{}
+	if err := storage.ReadStateFile(stateFile, known); err != nil {
+		t.Fatalf("ReadStateFile failed: %v", err)
+	}
+	if work := known["kca://work/"+url]; work == nil || !work.ReviewIDs[url+"-1"] || !work.ReviewIDs[url+"-2"] {
+		t.Errorf("Expected both reviews in the state file, got %v", known)
+	}
+}
@@ -0,0 +1,42 @@
+package app
+
+import (
+	"testing"
+
+	"github.com/rizkirmdhnnn/goodreadscrape/internal/langdetect"
+	"github.com/rizkirmdhnnn/goodreadscrape/internal/models"
+)
+
+func TestMislabelled(t *testing.T) {
+	tests := []struct {
+		name     string
+		text     string
+		fetched  string
+		expected int
+	}{
+		{"Short English review fetched as Indonesian", "5 stars", "id", 0},
+		{"One word review", "Good", "id", 0},
+		{"English review fetched as Indonesian", "Easily one of my favourite reads this year, and the last chapter made me cry.", "id", 1},
+		{"Indonesian review fetched as Indonesian", "Buku ini bagus sekali dan bikin saya terharu sampai halaman penutup.", "id", 0},
+		{"Review fetched in every language", "Easily one of my favourite reads this year, and the last chapter made me cry.", "", 0},
+		{"Malay review fetched as Malay", "Saya beli buku ini di kedai berhampiran rumah dan tidak menyesal langsung.", "ms", 0},
+		{"Tagalog review fetched as Tagalog", "Sobrang ganda ng librong ito, hindi ko mabitawan hanggang matapos ko ang huling kabanata.", "tl", 0},
+		{"Ukrainian review fetched as Ukrainian", "Дуже цікава книжка, раджу її всім своїм друзям", "uk", 0},
+		{"Persian review fetched as Persian", "این کتاب را در تعطیلات خواندم و خیلی دوستش داشتم", "fa", 0},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			detected := langdetect.Detect(tt.text)
+			review := models.Review{
+				ReviewText:         tt.text,
+				Language:           detected.Language,
+				LanguageConfidence: detected.Confidence,
+				FetchedLanguage:    tt.fetched,
+			}
+			if got := mislabelled([]models.Review{review}); got != tt.expected {
+				t.Errorf("Expected %d mislabelled, got %d (detected %s with %.3f)", tt.expected, got, detected.Language, detected.Confidence)
+			}
+		})
+	}
+}
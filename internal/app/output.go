@@ -0,0 +1,110 @@
+package app
+
+import (
+	"fmt"
+	"io"
+
+	"github.com/rizkirmdhnnn/goodreadscrape/internal/config"
+	"github.com/rizkirmdhnnn/goodreadscrape/internal/storage"
+)
+
+// Output is one destination that every batch of results is written to
+type Output struct {
+	Path        string
+	Format      storage.Format
+	Storage     storage.Storage
+	Partitioned bool // Path names a run directory of per-book directories
+}
+
+// BooksPath returns where the output stores book metadata. Partitioned
+// outputs place it in each book's directory themselves.
+func (o Output) BooksPath() string {
+	if o.Partitioned {
+		return o.Path
+	}
+	return o.Format.BooksPath(o.Path)
+}
+
+// outputError is a save failure of a single output
+type outputError struct {
+	Path string
+	Err  error
+}
+
+// newOutputs creates a storage backend for every configured output file
+func newOutputs(cfg *config.Config) ([]Output, error) {
+	dialect, err := storage.ParseCSVDialect(cfg.CSVDelimiter, cfg.CSVQuote, cfg.CSVNewlines, cfg.CSVBOM, cfg.CSVCRLF)
+	if err != nil {
+		return nil, err
+	}
+
+	opts := []storage.Option{
+		storage.WithTimeFormat(storage.TimeFormat(cfg.TimeFormat)),
+		storage.WithCSVDialect(dialect),
+		storage.WithCSVColumns(cfg.CSVColumns),
+		storage.WithParquetCodec(storage.ParquetCodec(cfg.ParquetCodec)),
+		storage.WithRowGroupSize(cfg.ParquetRowGroup),
+	}
+
+	outputs := make([]Output, 0, len(cfg.OutputFiles))
+	for _, path := range cfg.OutputFiles {
+		format, err := storage.ResolveFormat(cfg.Format, path)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", path, err)
+		}
+		var store storage.Storage
+		if cfg.Partition {
+			store, err = storage.NewPartitionedStorage(format, opts...)
+		} else {
+			store, err = storage.New(format, opts...)
+		}
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", path, err)
+		}
+		outputs = append(outputs, Output{Path: path, Format: format, Storage: store, Partitioned: cfg.Partition})
+	}
+	return outputs, nil
+}
+
+// saveAll runs save against every output, continuing past failures, and
+// returns the failures in output order
+func (app *ScraperApp) saveAll(save func(Output) error) []outputError {
+	app.saveMutex.Lock()
+	defer app.saveMutex.Unlock()
+
+	var errs []outputError
+	for _, output := range app.Outputs {
+		if err := save(output); err != nil {
+			errs = append(errs, outputError{Path: output.Path, Err: err})
+		}
+	}
+	return errs
+}
+
+// recordStatus reports the outcome of a book to backends that track it, such
+// as partitioned outputs, and returns the failures in output order
+func (app *ScraperApp) recordStatus(result storage.BookResult) []outputError {
+	return app.saveAll(func(o Output) error {
+		if recorder, ok := o.Storage.(storage.StatusRecorder); ok {
+			return recorder.RecordStatus(result, o.Path)
+		}
+		return nil
+	})
+}
+
+// closeOutputs flushes and closes backends that hold open handles, such as
+// SQLite and Parquet, and returns the failures in output order
+func (app *ScraperApp) closeOutputs() []outputError {
+	app.saveMutex.Lock()
+	defer app.saveMutex.Unlock()
+
+	var errs []outputError
+	for _, output := range app.Outputs {
+		if closer, ok := output.Storage.(io.Closer); ok {
+			if err := closer.Close(); err != nil {
+				errs = append(errs, outputError{Path: output.Path, Err: err})
+			}
+		}
+	}
+	return errs
+}
@@ -0,0 +1,84 @@
+package app
+
+import (
+	"errors"
+	"path/filepath"
+	"testing"
+
+	"github.com/rizkirmdhnnn/goodreadscrape/internal/config"
+	"github.com/rizkirmdhnnn/goodreadscrape/internal/models"
+	"github.com/rizkirmdhnnn/goodreadscrape/internal/storage"
+)
+
+// fakeStorage records saved reviews and book rows, and optionally fails every save
+type fakeStorage struct {
+	saved   int
+	fetched []int // ReviewsFetched of every book row saved
+	err     error
+}
+
+func (f *fakeStorage) SaveReviews(reviews []models.Review, outputPath string) error {
+	if f.err != nil {
+		return f.err
+	}
+	f.saved += len(reviews)
+	return nil
+}
+
+func (f *fakeStorage) SaveBookData(bookData models.BookData, outputPath string) error {
+	if f.err != nil {
+		return f.err
+	}
+	f.fetched = append(f.fetched, bookData.Fetched())
+	return nil
+}
+
+func TestNewOutputs_FormatPerExtension(t *testing.T) {
+	dir := t.TempDir()
+	cfg := &config.Config{OutputFiles: []string{
+		filepath.Join(dir, "out.csv"),
+		filepath.Join(dir, "out.jsonl"),
+		filepath.Join(dir, "out.db"),
+	}}
+
+	outputs, err := newOutputs(cfg)
+	if err != nil {
+		t.Fatalf("newOutputs failed: %v", err)
+	}
+
+	expected := []storage.Format{storage.FormatCSV, storage.FormatJSONL, storage.FormatSQLite}
+	if len(outputs) != len(expected) {
+		t.Fatalf("Expected %d outputs, got %d", len(expected), len(outputs))
+	}
+	for i, output := range outputs {
+		if output.Format != expected[i] {
+			t.Errorf("Output %s: expected format %s, got %s", output.Path, expected[i], output.Format)
+		}
+	}
+	if outputs[2].BooksPath() != outputs[2].Path {
+		t.Errorf("Expected SQLite books in the same database, got %s", outputs[2].BooksPath())
+	}
+}
+
+func TestSaveAll_ContinuesPastFailingOutput(t *testing.T) {
+	failing := &fakeStorage{err: errors.New("disk full")}
+	first, last := &fakeStorage{}, &fakeStorage{}
+
+	app := &ScraperApp{Outputs: []Output{
+		{Path: "a.csv", Storage: first},
+		{Path: "b.jsonl", Storage: failing},
+		{Path: "c.db", Storage: last},
+	}}
+
+	reviews := []models.Review{{ReviewID: "1"}, {ReviewID: "2"}}
+	errs := app.saveAll(func(o Output) error {
+		return o.Storage.SaveReviews(reviews, o.Path)
+	})
+
+	if len(errs) != 1 || errs[0].Path != "b.jsonl" {
+		t.Fatalf("Expected a single failure for b.jsonl, got %v", errs)
+	}
+	if first.saved != 2 || last.saved != 2 {
+		t.Errorf("Expected both healthy outputs to save 2 reviews, got %d and %d", first.saved, last.saved)
+	}
+}
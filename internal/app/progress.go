@@ -0,0 +1,84 @@
+package app
+
+import (
+	"errors"
+	"log"
+	"time"
+
+	"github.com/rizkirmdhnnn/goodreadscrape/internal/journal"
+	"github.com/rizkirmdhnnn/goodreadscrape/internal/models"
+	"github.com/rizkirmdhnnn/goodreadscrape/internal/storage"
+)
+
+// job is a URL to scrape, with the progress an earlier run recorded for it
+type job struct {
+	URL   string
+	Saved journal.Entry // Zero unless the run is resumed
+}
+
+// startJob records in the journal that a worker started on j
+func (app *ScraperApp) startJob(j job) {
+	if app.Journal == nil {
+		return
+	}
+
+	entry := j.Saved
+	entry.URL = j.URL
+	entry.Status = models.StatusInProgress
+	entry.UpdatedAt = time.Time{}
+	if err := app.Journal.Record(entry); err != nil {
+		log.Printf("⚠️ Failed to update journal for %s: %v", j.URL, err)
+	}
+}
+
+// finishJob records the outcome of a scraped URL in the journal and in outputs
+// that track books, and returns its status. saved reports whether the reviews
+// of this attempt reached at least one output; only then does the saved
+// pagination cursor move past them.
+func (app *ScraperApp) finishJob(result scrapeResult, saved bool) models.ScrapeStatus {
+	bookData := result.BookData
+
+	entry := result.Job.Saved
+	entry.URL = result.URL
+	entry.Error = ""
+	entry.UpdatedAt = time.Time{}
+	if bookData.Metadata.WorkID != "" {
+		entry.WorkID = bookData.Metadata.WorkID
+	}
+	if result.BookSaved {
+		entry.BookSaved = true
+	}
+	if saved {
+		entry.ReviewsFetched += result.Fetched
+		entry.ReviewsWritten += len(bookData.Reviews)
+		entry.NextPageToken = bookData.NextPageToken
+	}
+
+	err := result.Err
+	if err == nil && len(bookData.Reviews) > 0 && !saved {
+		err = errors.New("reviews could not be saved to any output")
+	}
+	switch {
+	case err == nil:
+		entry.Status = models.StatusComplete
+	case entry.ReviewsWritten > 0:
+		entry.Status = models.StatusPartial
+	default:
+		entry.Status = models.StatusFailed
+	}
+	if err != nil {
+		entry.Error = err.Error()
+	}
+
+	if app.Journal != nil {
+		if err := app.Journal.Record(entry); err != nil {
+			log.Printf("⚠️ Failed to update journal for %s: %v", result.URL, err)
+		}
+	}
+
+	status := storage.BookResult{URL: result.URL, Book: bookData.Metadata, Status: entry.Status, Error: entry.Error}
+	for _, e := range app.recordStatus(status) {
+		log.Printf("❌ Failed to record status of %s in %s: %v", result.URL, e.Path, e.Err)
+	}
+	return entry.Status
+}
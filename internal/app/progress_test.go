@@ -0,0 +1,291 @@
+package app
+
+import (
+	"context"
+	"database/sql"
+	"encoding/csv"
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/rizkirmdhnnn/goodreadscrape/internal/config"
+	"github.com/rizkirmdhnnn/goodreadscrape/internal/filter"
+	"github.com/rizkirmdhnnn/goodreadscrape/internal/journal"
+	"github.com/rizkirmdhnnn/goodreadscrape/internal/models"
+	"github.com/rizkirmdhnnn/goodreadscrape/internal/storage"
+)
+
+// fakeScraper serves two pages of reviews per book. The second page fails
+// while failSecondPage is set, like a run dying part way through a book.
+// Reviews in filters.Known are left out, as in an incremental fetch.
+type fakeScraper struct {
+	mu             sync.Mutex
+	failSecondPage bool
+	afters         map[string][]string // Page tokens requested, by URL
+	maxReviews     []int               // Review limits requested, in order
+}
+
+// review returns the review of a book page unless an earlier run saved it
+func (f *fakeScraper) review(bookData *models.BookData, id string, filters models.Filters) {
+	review := models.Review{ReviewID: id, WorkID: bookData.Metadata.WorkID}
+	if filters.Known != nil && filters.Known.Seen(review) {
+		return
+	}
+	bookData.Reviews = append(bookData.Reviews, review)
+}
+
+func (f *fakeScraper) ScrapeBookData(ctx context.Context, bookURL string, maxReviews int, filters models.Filters) (models.BookData, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.afters[bookURL] = append(f.afters[bookURL], filters.After)
+	f.maxReviews = append(f.maxReviews, maxReviews)
+
+	metadata := models.BookMetadata{WorkID: "kca://work/" + bookURL, URL: bookURL, Title: bookURL}
+	bookData := models.BookData{Metadata: metadata}
+	if filters.After == "" {
+		f.review(&bookData, bookURL+"-1", filters)
+		if f.failSecondPage {
+			bookData.NextPageToken = "page-2"
+			return bookData, errors.New("connection reset")
+		}
+	}
+	f.review(&bookData, bookURL+"-2", filters)
+	return bookData, nil
+}
+
+func (f *fakeScraper) ScrapeBookDataFromHTML(ctx context.Context, bookURL string, html []byte, maxReviews int, filters models.Filters) (models.BookData, error) {
+	return f.ScrapeBookData(ctx, bookURL, maxReviews, filters)
+}
+
+func (f *fakeScraper) FetchBookPage(ctx context.Context, bookURL string) ([]byte, error) {
+	return nil, nil
+}
+
+func (f *fakeScraper) ExtractBookMetadata(ctx context.Context, bookURL string) (models.BookMetadata, error) {
+	return models.BookMetadata{}, nil
+}
+
+func (f *fakeScraper) ExtractWorkID(ctx context.Context, bookURL string) (string, error) {
+	return "", nil
+}
+
+func (f *fakeScraper) FetchReviewsGraphQL(ctx context.Context, workID string, maxReviews int, languageCode string, bookMetadata models.BookMetadata) ([]models.Review, error) {
+	return nil, nil
+}
+
+func (f *fakeScraper) RetryCount() int64 {
+	return 0
+}
+
+func TestRun_ResumesFromJournal(t *testing.T) {
+	dir := t.TempDir()
+	url := "https://www.goodreads.com/book/show/1"
+	cfg := &config.Config{
+		InputURL:    url,
+		Concurrency: 1,
+		MaxReviews:  10,
+		OutputFiles: []string{filepath.Join(dir, "out.jsonl")},
+		JournalFile: filepath.Join(dir, "out.journal.jsonl"),
+	}
+
+	outputs, err := newOutputs(cfg)
+	if err != nil {
+		t.Fatal(err)
+	}
+	store := &fakeStorage{}
+	outputs[0].Storage = store
+
+	scraper := &fakeScraper{failSecondPage: true, afters: make(map[string][]string)}
+	app := &ScraperApp{Config: cfg, Scraper: scraper, Outputs: outputs}
+	app.Run()
+
+	j, err := journal.Open(cfg.JournalFile)
+	if err != nil {
+		t.Fatalf("Failed to open journal: %v", err)
+	}
+	entry, _ := j.Entry(url)
+	j.Close()
+	if entry.Status != models.StatusPartial || entry.NextPageToken != "page-2" || entry.ReviewsWritten != 1 {
+		t.Fatalf("Expected a partial entry at page-2 with 1 review, got %+v", entry)
+	}
+
+	// Resume: the book continues from its saved page, then is skipped
+	scraper.failSecondPage = false
+	resumed := &config.Config{Resume: cfg.JournalFile, Concurrency: 1, MaxReviews: 10, JournalFile: cfg.JournalFile}
+	for range 2 {
+		app := &ScraperApp{Config: resumed, Scraper: scraper, Outputs: outputs}
+		app.Run()
+	}
+
+	if got := scraper.afters[url]; len(got) != 2 || got[1] != "page-2" {
+		t.Errorf("Expected a first attempt and one resumed from page-2, got %q", got)
+	}
+	if store.saved != 2 {
+		t.Errorf("Expected 2 reviews saved without duplicates, got %d", store.saved)
+	}
+}
+
+func TestRun_FilteredReviewsAdvanceJournal(t *testing.T) {
+	dir := t.TempDir()
+	url := "https://www.goodreads.com/book/show/1"
+	cfg := &config.Config{
+		InputURL:    url,
+		Concurrency: 1,
+		MaxReviews:  10,
+		OutputFiles: []string{filepath.Join(dir, "out.jsonl")},
+		JournalFile: filepath.Join(dir, "out.journal.jsonl"),
+	}
+
+	outputs, err := newOutputs(cfg)
+	if err != nil {
+		t.Fatal(err)
+	}
+	store := &fakeStorage{}
+	outputs[0].Storage = store
+
+	// The fake reviews have no text, so every one is filtered out
+	scraper := &fakeScraper{failSecondPage: true, afters: make(map[string][]string)}
+	app := &ScraperApp{Config: cfg, Scraper: scraper, Outputs: outputs, Filter: filter.NewPipeline(filter.MinWords(1))}
+	app.Run()
+
+	j, err := journal.Open(cfg.JournalFile)
+	if err != nil {
+		t.Fatalf("Failed to open journal: %v", err)
+	}
+	entry, _ := j.Entry(url)
+	j.Close()
+	if entry.NextPageToken != "page-2" || entry.ReviewsFetched != 1 || entry.ReviewsWritten != 0 {
+		t.Errorf("Expected the cursor past the filtered page with 1 review fetched and nothing written, got %+v", entry)
+	}
+	if store.saved != 0 {
+		t.Errorf("Expected no reviews saved, got %d", store.saved)
+	}
+	if len(store.fetched) != 1 || store.fetched[0] != 1 {
+		t.Errorf("Expected one book row counting the filtered review as fetched, got %v", store.fetched)
+	}
+	if removed := app.Filter.Removed()[0].Removed; removed != 1 {
+		t.Errorf("Expected 1 review removed, got %d", removed)
+	}
+
+	// Resuming fetches only what is left of the limit, filtered or not
+	scraper.failSecondPage = false
+	resumed := &config.Config{Resume: cfg.JournalFile, Concurrency: 1, MaxReviews: 10, JournalFile: cfg.JournalFile}
+	app = &ScraperApp{Config: resumed, Scraper: scraper, Outputs: outputs, Filter: filter.NewPipeline(filter.MinWords(1))}
+	app.Run()
+	if got := scraper.maxReviews; len(got) != 2 || got[1] != 9 {
+		t.Errorf("Expected the resumed fetch limited to 9 reviews, got %v", got)
+	}
+}
+
+func TestRun_ResumedBookKeepsOneBooksRow(t *testing.T) {
+	dir := t.TempDir()
+	url := "https://www.goodreads.com/book/show/1"
+	cfg := &config.Config{
+		InputURL:    url,
+		Concurrency: 1,
+		MaxReviews:  10,
+		OutputFiles: []string{filepath.Join(dir, "out.csv"), filepath.Join(dir, "out.jsonl")},
+		JournalFile: filepath.Join(dir, "out.journal.jsonl"),
+	}
+
+	scraper := &fakeScraper{failSecondPage: true, afters: make(map[string][]string)}
+	outputs, err := newOutputs(cfg)
+	if err != nil {
+		t.Fatal(err)
+	}
+	app := &ScraperApp{Config: cfg, Scraper: scraper, Outputs: outputs}
+	app.Run()
+
+	// The resumed run finishes the book without writing its metadata again
+	scraper.failSecondPage = false
+	resumed := &config.Config{Resume: cfg.JournalFile, Concurrency: 1, MaxReviews: 10, JournalFile: cfg.JournalFile}
+	outputs, err = newOutputs(&config.Config{OutputFiles: cfg.OutputFiles})
+	if err != nil {
+		t.Fatal(err)
+	}
+	app = &ScraperApp{Config: resumed, Scraper: scraper, Outputs: outputs}
+	app.Run()
+
+	for _, output := range outputs {
+		data, err := os.ReadFile(output.BooksPath())
+		if err != nil {
+			t.Fatalf("Failed to read %s: %v", output.BooksPath(), err)
+		}
+		lines := strings.Split(strings.TrimSpace(string(data)), "\n")
+		rows := len(lines)
+		if output.Format == storage.FormatCSV {
+			rows-- // Header
+		}
+		if rows != 1 {
+			t.Errorf("%s: expected 1 books row, got %d:\n%s", output.BooksPath(), rows, data)
+		}
+	}
+}
+
+func TestRun_ResumedBookKeepsBookColumns(t *testing.T) {
+	dir := t.TempDir()
+	url := "https://www.goodreads.com/book/show/1"
+	csvPath := filepath.Join(dir, "out.csv")
+	dbPath := filepath.Join(dir, "out.db")
+	cfg := &config.Config{
+		InputURL:    url,
+		Concurrency: 1,
+		MaxReviews:  10,
+		OutputFiles: []string{csvPath, dbPath},
+		CSVColumns:  []string{"WorkID", "ReviewID", "Title"},
+		JournalFile: filepath.Join(dir, "out.journal.jsonl"),
+	}
+
+	scraper := &fakeScraper{failSecondPage: true, afters: make(map[string][]string)}
+	outputs, err := newOutputs(cfg)
+	if err != nil {
+		t.Fatal(err)
+	}
+	app := &ScraperApp{Config: cfg, Scraper: scraper, Outputs: outputs}
+	app.Run()
+
+	// A fresh run resumes the book, with backends that have not seen it yet
+	scraper.failSecondPage = false
+	resumed := &config.Config{Resume: cfg.JournalFile, Concurrency: 1, MaxReviews: 10, JournalFile: cfg.JournalFile}
+	outputs, err = newOutputs(&config.Config{OutputFiles: cfg.OutputFiles, CSVColumns: cfg.CSVColumns})
+	if err != nil {
+		t.Fatal(err)
+	}
+	app = &ScraperApp{Config: resumed, Scraper: scraper, Outputs: outputs}
+	app.Run()
+
+	file, err := os.Open(csvPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer file.Close()
+	records, err := csv.NewReader(file).ReadAll()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(records) != 3 {
+		t.Fatalf("Expected header + 2 reviews, got %v", records)
+	}
+	for _, record := range records[1:] {
+		if record[2] != url {
+			t.Errorf("Expected review %s to carry the book title, got %q", record[1], record[2])
+		}
+	}
+
+	// Upserted books are rewritten with the reviews of both runs
+	db, err := sql.Open("sqlite", dbPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer db.Close()
+	var books, fetched int
+	if err := db.QueryRow("SELECT COUNT(*), MAX(reviews_fetched) FROM books").Scan(&books, &fetched); err != nil {
+		t.Fatal(err)
+	}
+	if books != 1 || fetched != 2 {
+		t.Errorf("Expected 1 book with 2 reviews fetched, got %d books with %d", books, fetched)
+	}
+}
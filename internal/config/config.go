@@ -3,7 +3,19 @@ package config
 import (
 	"flag"
 	"fmt"
+	"net/url"
+	"os"
+	"path/filepath"
+	"slices"
+	"strconv"
+	"strings"
 	"time"
+
+	"github.com/rizkirmdhnnn/goodreadscrape/internal/filter"
+	"github.com/rizkirmdhnnn/goodreadscrape/internal/journal"
+	"github.com/rizkirmdhnnn/goodreadscrape/internal/models"
+	"github.com/rizkirmdhnnn/goodreadscrape/internal/scraper"
+	"github.com/rizkirmdhnnn/goodreadscrape/internal/storage"
 )
 
 // Config holds all configuration for the application
@@ -14,8 +26,53 @@ type Config struct {
 	InputFile   string
 	InputURL    string
 	MaxReviews  int
-	OutputFile  string
-	Language    string
+	OutputFiles []string
+	Format      string
+	Compress    string
+	Partition   bool
+	JournalFile string
+	Resume      string
+	Incremental bool
+	StateFile   string
+	Language    string // Comma-separated language codes, or all
+	TimeFormat  string
+	BaseURL     string
+	GraphQLURL  string
+
+	// Review filters applied by the GraphQL query
+	MaxPerLanguage bool // Apply MaxReviews to each language rather than overall
+	Sort           string
+	Stars          string
+	TextOnly       bool
+	Search         string
+
+	// Client-side review filters, from -filters merged with their flags
+	FilterFile    string
+	ReviewFilters filter.Config
+
+	// CSV dialect and reviews file columns
+	CSVDelimiter string
+	CSVQuote     string
+	CSVNewlines  string
+	CSVBOM       bool
+	CSVCRLF      bool
+	CSVColumns   []string
+
+	// Parquet output settings
+	ParquetCodec    string
+	ParquetRowGroup int64
+
+	// Retry policy for page and GraphQL requests
+	RetryAttempts  int
+	RetryBaseDelay time.Duration
+	RetryMaxDelay  time.Duration
+	RetryJitter    float64
+
+	// Shared rate limits, in requests per second (0 disables)
+	PageRPS      float64
+	PageBurst    int
+	GraphQLRPS   float64
+	GraphQLBurst int
 }
 
 // ParseFlags parses command-line flags and returns a Config struct
@@ -25,8 +82,52 @@ func ParseFlags() *Config {
 	verbose := flag.Bool("verbose", false, "Enable verbose logging")
 	inputFile := flag.String("f", "", "Text file containing Goodreads URLs (one per line)")
 	maxReviews := flag.Int("m", 100, "Maximum number of reviews to scrape per book")
-	outputFile := flag.String("o", "", "Output CSV file (default: auto-generated with timestamp)")
-	language := flag.String("l", "id", "Language code for reviews")
+	var outputFiles stringList
+	flag.Var(&outputFiles, "o", "Output file; repeat to write several outputs, each in the format of its extension (default: auto-generated with timestamp)")
+	format := flag.String("format", "", "Output format: csv, jsonl, sqlite or parquet (default: inferred from the -o extension, else csv)")
+	partition := flag.Bool("partition", false, "Write each book to its own directory under a run directory named after each -o: <run>/<book>/reviews.<ext> and book.json, plus <run>/manifest.json")
+	journalFile := flag.String("journal", "", "Run journal recording the progress of every URL, for -resume (default: <first output name>.journal.jsonl, timestamped if it exists)")
+	resume := flag.String("resume", "", "Resume the run recorded in this journal: skip completed books and continue partial ones from where they stopped")
+	incremental := flag.Bool("incremental", false, "Fetch only reviews newer than those already in the outputs (or -state file): newest first, stopping at the first saved review")
+	stateFile := flag.String("state", "", "State file of saved review IDs per book for -incremental, read at the start and updated as books are saved (implies -incremental)")
+	compress := flag.String("compress", "", "Compress CSV and JSON Lines outputs: gzip or zstd, adding .gz or .zst to their paths (default: by the -o extension)")
+	language := flag.String("l", "id", "Comma-separated language codes of reviews to fetch, each with queries of its own, or all for every language")
+	perLanguage := flag.Bool("per-language", false, "Apply -m to each language of -l rather than to all of them together")
+	sort := flag.String("sort", "default", "Review order: default (most popular first), newest or oldest")
+	stars := flag.String("stars", "", "Only fetch reviews with this star rating, or range such as 4-5")
+	textOnly := flag.Bool("text-only", false, "Only fetch reviews with text, leaving out ratings without a review")
+	search := flag.String("search", "", "Only fetch reviews matching this search term")
+	filterFile := flag.String("filters", "", "JSON file of client-side review filters; the filter flags below add to it")
+	minWords := flag.Int("min-words", 0, "Drop reviews with fewer words than this")
+	noSpoilers := flag.Bool("no-spoilers", false, "Drop reviews flagged as containing spoilers")
+	since := flag.String("since", "", "Drop reviews created before this date (2006-01-02) or RFC 3339 time")
+	until := flag.String("until", "", "Drop reviews created after this date (inclusive) or from this RFC 3339 time")
+	require := flag.String("require", "", "Comma-separated keywords every kept review must contain (case-insensitive)")
+	exclude := flag.String("exclude", "", "Comma-separated keywords that drop a review containing any of them (case-insensitive)")
+	var requireRegex, excludeRegex stringList
+	flag.Var(&requireRegex, "require-regex", "Regular expression every kept review must match; repeatable")
+	flag.Var(&excludeRegex, "exclude-regex", "Regular expression that drops matching reviews; repeatable")
+	var ratings intList
+	flag.Var(&ratings, "ratings", "Comma-separated star ratings to keep, e.g. 1,5; drops unrated reviews")
+	timeFormat := flag.String("time-format", "date", "Timestamp format in output: rfc3339, date or epoch-ms")
+	csvDelimiter := flag.String("csv-delimiter", "comma", "CSV delimiter: comma, tab, semicolon or a single character")
+	csvQuote := flag.String("csv-quote", "minimal", "CSV quoting: minimal (only when needed) or all")
+	csvNewlines := flag.String("csv-newlines", "keep", "Line breaks inside CSV fields: keep, space or escape (as \\n)")
+	csvBOM := flag.Bool("csv-bom", false, "Start new CSV files with a UTF-8 byte order mark (for Excel)")
+	csvCRLF := flag.Bool("csv-crlf", false, "End CSV lines with \\r\\n instead of \\n")
+	csvColumns := flag.String("csv-columns", "", "Comma-separated, ordered columns of the CSV reviews file, from review and book fields (default: all review fields)")
+	parquetCodec := flag.String("parquet-codec", "snappy", "Parquet compression codec: snappy, zstd, gzip or none")
+	parquetRowGroup := flag.Int64("parquet-row-group", storage.DefaultRowGroupSize, "Rows buffered per Parquet row group before flushing to disk")
+	baseURL := flag.String("base-url", "", "Base URL for Goodreads pages (env: GOODREADS_BASE_URL)")
+	graphqlURL := flag.String("graphql-url", "", "GraphQL endpoint for reviews (env: GOODREADS_GRAPHQL_URL)")
+	retryAttempts := flag.Int("retry-attempts", 4, "Maximum attempts per request, including the first (1 disables retries)")
+	retryBaseDelay := flag.Duration("retry-base-delay", 1*time.Second, "Delay before the first retry, doubled on each further retry")
+	retryMaxDelay := flag.Duration("retry-max-delay", 30*time.Second, "Maximum delay between retries, including Retry-After")
+	retryJitter := flag.Float64("retry-jitter", 0.2, "Fraction of each retry delay to randomise (0-1)")
+	pageRPS := flag.Float64("page-rps", 1, "Maximum HTML page requests per second across all workers (0 disables)")
+	pageBurst := flag.Int("page-burst", 2, "Burst size for HTML page requests")
+	graphqlRPS := flag.Float64("graphql-rps", 1, "Maximum GraphQL requests per second across all workers (0 disables)")
+	graphqlBurst := flag.Int("graphql-burst", 2, "Burst size for GraphQL requests")
 
 	flag.Parse()
 
@@ -43,14 +144,104 @@ func ParseFlags() *Config {
 		InputFile:   *inputFile,
 		InputURL:    url,
 		MaxReviews:  *maxReviews,
-		OutputFile:  *outputFile,
+		OutputFiles: outputFiles,
+		Format:      *format,
+		Compress:    *compress,
+		Partition:   *partition,
+		JournalFile: *journalFile,
+		Resume:      *resume,
+		Incremental: *incremental || *stateFile != "",
+		StateFile:   *stateFile,
 		Language:    *language,
+		TimeFormat:  *timeFormat,
+		BaseURL:     envOrDefault(*baseURL, "GOODREADS_BASE_URL"),
+		GraphQLURL:  envOrDefault(*graphqlURL, "GOODREADS_GRAPHQL_URL"),
+
+		MaxPerLanguage: *perLanguage,
+		Sort:           *sort,
+		Stars:          *stars,
+		TextOnly:       *textOnly,
+		Search:         *search,
+
+		FilterFile: *filterFile,
+		ReviewFilters: filter.Config{
+			MinWords:        *minWords,
+			ExcludeSpoilers: *noSpoilers,
+			Since:           *since,
+			Until:           *until,
+			Require:         splitList(*require),
+			Exclude:         splitList(*exclude),
+			RequireRegex:    requireRegex,
+			ExcludeRegex:    excludeRegex,
+			Ratings:         ratings,
+		},
+
+		CSVDelimiter: *csvDelimiter,
+		CSVQuote:     *csvQuote,
+		CSVNewlines:  *csvNewlines,
+		CSVBOM:       *csvBOM,
+		CSVCRLF:      *csvCRLF,
+		CSVColumns:   splitList(*csvColumns),
+
+		ParquetCodec:    *parquetCodec,
+		ParquetRowGroup: *parquetRowGroup,
+
+		RetryAttempts:  *retryAttempts,
+		RetryBaseDelay: *retryBaseDelay,
+		RetryMaxDelay:  *retryMaxDelay,
+		RetryJitter:    *retryJitter,
+
+		PageRPS:      *pageRPS,
+		PageBurst:    *pageBurst,
+		GraphQLRPS:   *graphqlRPS,
+		GraphQLBurst: *graphqlBurst,
+	}
+
+	// A resumed run continues its journal and, unless -o is given, its outputs.
+	// Unreadable journals are reported by Validate.
+	if cfg.Resume != "" {
+		cfg.JournalFile = cfg.Resume
+		if run, err := journal.ReadRun(cfg.Resume); err == nil {
+			if len(cfg.OutputFiles) == 0 {
+				cfg.OutputFiles = run.OutputFiles
+				cfg.Format = run.Format
+				cfg.Partition = run.Partition
+			}
+			// Saved page tokens only continue the query they came from
+			cfg.Incremental = cfg.Incremental || run.Incremental
+			if cfg.StateFile == "" {
+				cfg.StateFile = run.StateFile
+			}
+			if run.Filters != nil {
+				cfg.Language = run.Filters.Language
+				cfg.MaxPerLanguage = run.Filters.PerLanguage
+				cfg.Sort = run.Filters.Sort
+				cfg.Stars = run.Filters.Stars
+				cfg.TextOnly = run.Filters.TextOnly
+				cfg.Search = run.Filters.Search
+			}
+		}
 	}
 
 	// Set default output file if not provided
-	if cfg.OutputFile == "" {
+	if len(cfg.OutputFiles) == 0 {
+		ext := storage.FormatCSV.Extension()
+		if f, err := storage.ParseFormat(cfg.Format); err == nil {
+			ext = f.Extension()
+		}
 		timestamp := time.Now().Format("20060102_150405")
-		cfg.OutputFile = fmt.Sprintf("results/goodreads_reviews_%s.csv", timestamp)
+		cfg.OutputFiles = []string{fmt.Sprintf("results/goodreads_reviews_%s%s", timestamp, ext)}
+	}
+
+	// Compress outputs that support it; invalid values are reported by Validate
+	if compression, err := storage.ParseCompression(cfg.Compress); err == nil && compression != storage.CompressionNone {
+		for i, output := range cfg.OutputFiles {
+			cfg.OutputFiles[i] = withCompression(output, cfg.Format, compression)
+		}
+	}
+
+	if cfg.JournalFile == "" {
+		cfg.JournalFile = journalPath(cfg.OutputFiles[0], time.Now())
 	}
 
 	return cfg
@@ -61,5 +252,212 @@ func (c *Config) Validate() error {
 	if c.APIKey == "" {
 		return fmt.Errorf("API key is required. Use -api flag to provide it")
 	}
+	if c.TimeFormat != "" {
+		if _, err := storage.ParseTimeFormat(c.TimeFormat); err != nil {
+			return err
+		}
+	}
+	if c.Format != "" {
+		if _, err := storage.ParseFormat(c.Format); err != nil {
+			return err
+		}
+	}
+	if _, err := storage.ParseCompression(c.Compress); err != nil {
+		return err
+	}
+	if _, err := scraper.ParseLanguages(c.Language); err != nil {
+		return err
+	}
+	sort, err := scraper.ParseSort(c.Sort)
+	if err != nil {
+		return err
+	}
+	if c.Incremental && sort == models.SortOldest {
+		return fmt.Errorf("incremental runs fetch the newest reviews first and cannot sort by oldest")
+	}
+	if _, _, err := scraper.ParseStars(c.Stars); err != nil {
+		return err
+	}
+	filters, err := c.ReviewFilterConfig()
+	if err != nil {
+		return err
+	}
+	if _, err := filters.Pipeline(); err != nil {
+		return fmt.Errorf("invalid review filter: %w", err)
+	}
+	if c.Resume != "" {
+		if _, err := journal.ReadRun(c.Resume); err != nil {
+			return fmt.Errorf("cannot resume: %w", err)
+		}
+	}
+	seen := make(map[string]bool)
+	for _, output := range c.OutputFiles {
+		format, err := storage.ResolveFormat(c.Format, output)
+		if err != nil {
+			return err
+		}
+		if c.Resume != "" && format == storage.FormatParquet {
+			return fmt.Errorf("cannot resume into %s: Parquet files cannot be appended to", output)
+		}
+		if c.Incremental && c.StateFile == "" {
+			if err := checkReadable(format, c.CSVColumns); err != nil {
+				return fmt.Errorf("cannot run incrementally from %s: %w; use -state", output, err)
+			}
+		}
+		path := filepath.Clean(output)
+		if seen[path] {
+			return fmt.Errorf("output file %s is given more than once", output)
+		}
+		seen[path] = true
+	}
+	if _, err := storage.ParseCSVDialect(c.CSVDelimiter, c.CSVQuote, c.CSVNewlines, c.CSVBOM, c.CSVCRLF); err != nil {
+		return err
+	}
+	if err := storage.ValidateReviewColumns(c.CSVColumns); err != nil {
+		return err
+	}
+	if c.ParquetCodec != "" {
+		if _, err := storage.ParseParquetCodec(c.ParquetCodec); err != nil {
+			return err
+		}
+	}
+	if c.ParquetRowGroup < 0 {
+		return fmt.Errorf("parquet row group size must not be negative")
+	}
+	if c.RetryAttempts < 0 {
+		return fmt.Errorf("retry attempts must not be negative")
+	}
+	if c.RetryJitter < 0 || c.RetryJitter > 1 {
+		return fmt.Errorf("retry jitter must be between 0 and 1")
+	}
+	if c.PageRPS < 0 || c.GraphQLRPS < 0 {
+		return fmt.Errorf("rate limits must not be negative")
+	}
+	if err := validateEndpoint(c.BaseURL); err != nil {
+		return fmt.Errorf("invalid base URL: %w", err)
+	}
+	if err := validateEndpoint(c.GraphQLURL); err != nil {
+		return fmt.Errorf("invalid GraphQL URL: %w", err)
+	}
+	return nil
+}
+
+// ReviewFilterConfig returns the client-side review filters: those of the
+// filters file, with the filter flags added
+func (c *Config) ReviewFilterConfig() (filter.Config, error) {
+	if c.FilterFile == "" {
+		return c.ReviewFilters, nil
+	}
+	fromFile, err := filter.LoadConfig(c.FilterFile)
+	if err != nil {
+		return filter.Config{}, err
+	}
+	return fromFile.Merge(c.ReviewFilters), nil
+}
+
+// checkReadable reports whether the reviews saved in an output of format can
+// be read back for an incremental run
+func checkReadable(format storage.Format, csvColumns []string) error {
+	switch format {
+	case storage.FormatParquet:
+		return fmt.Errorf("saved reviews cannot be read back from Parquet outputs")
+	case storage.FormatCSV:
+		if len(csvColumns) > 0 && (!slices.Contains(csvColumns, "WorkID") || !slices.Contains(csvColumns, "ReviewID")) {
+			return fmt.Errorf("-csv-columns must include WorkID and ReviewID")
+		}
+	}
+	return nil
+}
+
+// journalPath returns the default journal path for a run writing to
+// outputPath, e.g. results/reviews.csv.gz -> results/reviews.journal.jsonl.
+// If that journal exists, from an earlier run appending to the same output,
+// the run's timestamp is added to the name.
+func journalPath(outputPath string, now time.Time) string {
+	stem := storage.PartitionDir(outputPath)
+	path := stem + ".journal.jsonl"
+	if _, err := os.Stat(path); err == nil {
+		path = stem + "_" + now.Format("20060102_150405") + ".journal.jsonl"
+	}
+	return path
+}
+
+// withCompression adds the compression extension to an output path, unless
+// the path is already compressed or its format cannot be compressed
+func withCompression(path string, format string, compression storage.Compression) string {
+	if _, existing := storage.SplitCompression(path); existing != storage.CompressionNone {
+		return path
+	}
+	if f, err := storage.ResolveFormat(format, path); err != nil || !f.Compressible() {
+		return path
+	}
+	return path + compression.Extension()
+}
+
+// stringList is a flag.Value collecting every occurrence of a repeated flag
+type stringList []string
+
+func (l *stringList) String() string {
+	return strings.Join(*l, ",")
+}
+
+func (l *stringList) Set(value string) error {
+	*l = append(*l, value)
+	return nil
+}
+
+// intList is a flag.Value collecting comma-separated integers
+type intList []int
+
+func (l *intList) String() string {
+	items := make([]string, 0, len(*l))
+	for _, n := range *l {
+		items = append(items, strconv.Itoa(n))
+	}
+	return strings.Join(items, ",")
+}
+
+func (l *intList) Set(value string) error {
+	for _, item := range splitList(value) {
+		n, err := strconv.Atoi(item)
+		if err != nil {
+			return fmt.Errorf("%q is not a number", item)
+		}
+		*l = append(*l, n)
+	}
+	return nil
+}
+
+// splitList splits a comma-separated flag value, dropping empty items
+func splitList(value string) []string {
+	var items []string
+	for _, item := range strings.Split(value, ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			items = append(items, item)
+		}
+	}
+	return items
+}
+
+// validateEndpoint checks that an optional endpoint is an absolute http(s) URL
+func validateEndpoint(rawURL string) error {
+	if rawURL == "" {
+		return nil
+	}
+	parsed, err := url.Parse(rawURL)
+	if err != nil {
+		return err
+	}
+	if (parsed.Scheme != "http" && parsed.Scheme != "https") || parsed.Host == "" {
+		return fmt.Errorf("%q must be an absolute http(s) URL", rawURL)
+	}
 	return nil
 }
+
+// envOrDefault returns value if set, otherwise the named environment variable
+func envOrDefault(value string, envKey string) string {
+	if value != "" {
+		return value
+	}
+	return os.Getenv(envKey)
+}
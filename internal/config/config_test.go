@@ -1,7 +1,13 @@
 package config
 
 import (
+	"os"
+	"path/filepath"
 	"testing"
+	"time"
+
+	"github.com/rizkirmdhnnn/goodreadscrape/internal/filter"
+	"github.com/rizkirmdhnnn/goodreadscrape/internal/storage"
 )
 
 func TestConfig_Validate(t *testing.T) {
@@ -24,6 +30,230 @@ func TestConfig_Validate(t *testing.T) {
 			},
 			wantErr: true,
 		},
+		{
+			name: "Valid custom endpoints",
+			config: Config{
+				APIKey:     "test-api-key",
+				BaseURL:    "http://127.0.0.1:8080",
+				GraphQLURL: "http://127.0.0.1:8080/graphql",
+			},
+			wantErr: false,
+		},
+		{
+			name: "Invalid time format",
+			config: Config{
+				APIKey:     "test-api-key",
+				TimeFormat: "unix",
+			},
+			wantErr: true,
+		},
+		{
+			name: "Invalid output format",
+			config: Config{
+				APIKey: "test-api-key",
+				Format: "xml",
+			},
+			wantErr: true,
+		},
+		{
+			name: "Valid multiple outputs",
+			config: Config{
+				APIKey:      "test-api-key",
+				OutputFiles: []string{"results/out.csv", "results/out.jsonl", "results/out.db"},
+			},
+			wantErr: false,
+		},
+		{
+			name: "Duplicate output",
+			config: Config{
+				APIKey:      "test-api-key",
+				OutputFiles: []string{"results/out.csv", "./results/out.csv"},
+			},
+			wantErr: true,
+		},
+		{
+			name: "Invalid CSV delimiter",
+			config: Config{
+				APIKey:       "test-api-key",
+				CSVDelimiter: "::",
+			},
+			wantErr: true,
+		},
+		{
+			name: "Unknown CSV column",
+			config: Config{
+				APIKey:     "test-api-key",
+				CSVColumns: []string{"ReviewID", "Stars"},
+			},
+			wantErr: true,
+		},
+		{
+			name: "Invalid compression",
+			config: Config{
+				APIKey:   "test-api-key",
+				Compress: "bzip2",
+			},
+			wantErr: true,
+		},
+		{
+			name: "Compressed SQLite output",
+			config: Config{
+				APIKey:      "test-api-key",
+				OutputFiles: []string{"results/out.db.gz"},
+			},
+			wantErr: true,
+		},
+		{
+			name: "Resume missing journal",
+			config: Config{
+				APIKey: "test-api-key",
+				Resume: "results/missing.journal.jsonl",
+			},
+			wantErr: true,
+		},
+		{
+			name: "Incremental from CSV output",
+			config: Config{
+				APIKey:      "test-api-key",
+				OutputFiles: []string{"results/out.csv.gz"},
+				Incremental: true,
+			},
+			wantErr: false,
+		},
+		{
+			name: "Incremental from CSV without review IDs",
+			config: Config{
+				APIKey:      "test-api-key",
+				OutputFiles: []string{"results/out.csv"},
+				CSVColumns:  []string{"WorkID", "ReviewText"},
+				Incremental: true,
+			},
+			wantErr: true,
+		},
+		{
+			name: "Incremental from Parquet output",
+			config: Config{
+				APIKey:      "test-api-key",
+				OutputFiles: []string{"results/out.parquet"},
+				Incremental: true,
+			},
+			wantErr: true,
+		},
+		{
+			name: "Incremental Parquet output with state file",
+			config: Config{
+				APIKey:      "test-api-key",
+				OutputFiles: []string{"results/out.parquet"},
+				Incremental: true,
+				StateFile:   "results/state.json",
+			},
+			wantErr: false,
+		},
+		{
+			name: "Valid review filters",
+			config: Config{
+				APIKey:   "test-api-key",
+				Sort:     "oldest",
+				Stars:    "4-5",
+				TextOnly: true,
+				Search:   "ending",
+			},
+			wantErr: false,
+		},
+		{
+			name: "Several languages",
+			config: Config{
+				APIKey:         "test-api-key",
+				Language:       "id,en",
+				MaxPerLanguage: true,
+			},
+			wantErr: false,
+		},
+		{
+			name: "All languages with others",
+			config: Config{
+				APIKey:   "test-api-key",
+				Language: "all,en",
+			},
+			wantErr: true,
+		},
+		{
+			name: "Invalid sort order",
+			config: Config{
+				APIKey: "test-api-key",
+				Sort:   "random",
+			},
+			wantErr: true,
+		},
+		{
+			name: "Invalid star rating",
+			config: Config{
+				APIKey: "test-api-key",
+				Stars:  "6",
+			},
+			wantErr: true,
+		},
+		{
+			name: "Incremental sorted by oldest",
+			config: Config{
+				APIKey:      "test-api-key",
+				Incremental: true,
+				Sort:        "oldest",
+			},
+			wantErr: true,
+		},
+		{
+			name: "Valid review filter flags",
+			config: Config{
+				APIKey: "test-api-key",
+				ReviewFilters: filter.Config{
+					MinWords: 10,
+					Since:    "2024-01-01",
+					Ratings:  []int{1, 5},
+				},
+			},
+			wantErr: false,
+		},
+		{
+			name: "Invalid review filter regex",
+			config: Config{
+				APIKey:        "test-api-key",
+				ReviewFilters: filter.Config{ExcludeRegex: []string{"[a-"}},
+			},
+			wantErr: true,
+		},
+		{
+			name: "Missing filters file",
+			config: Config{
+				APIKey:     "test-api-key",
+				FilterFile: "missing-filters.json",
+			},
+			wantErr: true,
+		},
+		{
+			name: "Invalid parquet codec",
+			config: Config{
+				APIKey:       "test-api-key",
+				ParquetCodec: "lzo",
+			},
+			wantErr: true,
+		},
+		{
+			name: "Invalid retry jitter",
+			config: Config{
+				APIKey:      "test-api-key",
+				RetryJitter: 1.5,
+			},
+			wantErr: true,
+		},
+		{
+			name: "Invalid base URL",
+			config: Config{
+				APIKey:  "test-api-key",
+				BaseURL: "127.0.0.1:8080",
+			},
+			wantErr: true,
+		},
 	}
 
 	for _, tt := range tests {
@@ -35,3 +265,51 @@ func TestConfig_Validate(t *testing.T) {
 		})
 	}
 }
+
+func TestWithCompression(t *testing.T) {
+	tests := []struct {
+		path     string
+		format   string
+		expected string
+	}{
+		{"results/out.csv", "", "results/out.csv.gz"},
+		{"results/out.jsonl", "", "results/out.jsonl.gz"},
+		{"results/out.csv.zst", "", "results/out.csv.zst"},
+		{"results/out.db", "", "results/out.db"},
+		{"results/out.parquet", "", "results/out.parquet"},
+		{"results/out", "sqlite", "results/out"},
+	}
+
+	for _, tt := range tests {
+		if got := withCompression(tt.path, tt.format, storage.CompressionGzip); got != tt.expected {
+			t.Errorf("withCompression(%q, %q) = %q, want %q", tt.path, tt.format, got, tt.expected)
+		}
+	}
+}
+
+func TestJournalPath(t *testing.T) {
+	dir := t.TempDir()
+	now := time.Date(2024, 1, 2, 15, 4, 5, 0, time.UTC)
+
+	tests := []struct {
+		input    string
+		expected string
+	}{
+		{filepath.Join(dir, "out.csv"), filepath.Join(dir, "out.journal.jsonl")},
+		{filepath.Join(dir, "out.jsonl.gz"), filepath.Join(dir, "out.journal.jsonl")},
+		{filepath.Join(dir, "run"), filepath.Join(dir, "run.journal.jsonl")},
+	}
+	for _, tt := range tests {
+		if got := journalPath(tt.input, now); got != tt.expected {
+			t.Errorf("journalPath(%q) = %q, want %q", tt.input, got, tt.expected)
+		}
+	}
+
+	// A journal left by an earlier run writing to the same output is kept
+	if err := os.WriteFile(filepath.Join(dir, "out.journal.jsonl"), nil, 0644); err != nil {
+		t.Fatal(err)
+	}
+	if got, expected := journalPath(filepath.Join(dir, "out.csv"), now), filepath.Join(dir, "out_20240102_150405.journal.jsonl"); got != expected {
+		t.Errorf("Expected %q next to an existing journal, got %q", expected, got)
+	}
+}
@@ -0,0 +1,149 @@
+package filter
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"os"
+	"regexp"
+	"slices"
+	"time"
+)
+
+// Config describes a pipeline, as set by flags or a JSON filters file
+type Config struct {
+	MinWords        int      `json:"min_words,omitempty"`
+	ExcludeSpoilers bool     `json:"exclude_spoilers,omitempty"`
+	Since           string   `json:"since,omitempty"` // Date or RFC 3339 time, inclusive
+	Until           string   `json:"until,omitempty"` // Date (inclusive) or RFC 3339 time (exclusive)
+	Require         []string `json:"require,omitempty"`
+	Exclude         []string `json:"exclude,omitempty"`
+	RequireRegex    []string `json:"require_regex,omitempty"`
+	ExcludeRegex    []string `json:"exclude_regex,omitempty"`
+	Ratings         []int    `json:"ratings,omitempty"`
+}
+
+// LoadConfig reads a JSON filters file
+func LoadConfig(path string) (Config, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return Config{}, fmt.Errorf("failed to read filters file: %w", err)
+	}
+
+	var c Config
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	decoder.DisallowUnknownFields()
+	if err := decoder.Decode(&c); err != nil {
+		return Config{}, fmt.Errorf("invalid filters file %s: %w", path, err)
+	}
+	return c, nil
+}
+
+// Merge returns c with the settings of other added: numbers and dates set in
+// other replace those of c, and lists are combined
+func (c Config) Merge(other Config) Config {
+	if other.MinWords > 0 {
+		c.MinWords = other.MinWords
+	}
+	c.ExcludeSpoilers = c.ExcludeSpoilers || other.ExcludeSpoilers
+	if other.Since != "" {
+		c.Since = other.Since
+	}
+	if other.Until != "" {
+		c.Until = other.Until
+	}
+	c.Require = slices.Concat(c.Require, other.Require)
+	c.Exclude = slices.Concat(c.Exclude, other.Exclude)
+	c.RequireRegex = slices.Concat(c.RequireRegex, other.RequireRegex)
+	c.ExcludeRegex = slices.Concat(c.ExcludeRegex, other.ExcludeRegex)
+	if len(other.Ratings) > 0 {
+		c.Ratings = other.Ratings
+	}
+	return c
+}
+
+// Pipeline builds the pipeline c describes. Filters run in a fixed order,
+// cheapest first; an empty Config gives an empty pipeline.
+func (c Config) Pipeline() (*Pipeline, error) {
+	var filters []Filter
+
+	if len(c.Ratings) > 0 {
+		for _, rating := range c.Ratings {
+			if rating < 1 || rating > 5 {
+				return nil, fmt.Errorf("invalid star rating %d (use 1 to 5)", rating)
+			}
+		}
+		filters = append(filters, Ratings(c.Ratings...))
+	}
+	if c.ExcludeSpoilers {
+		filters = append(filters, ExcludeSpoilers())
+	}
+
+	since, err := parseBound(c.Since, false)
+	if err != nil {
+		return nil, fmt.Errorf("invalid since: %w", err)
+	}
+	until, err := parseBound(c.Until, true)
+	if err != nil {
+		return nil, fmt.Errorf("invalid until: %w", err)
+	}
+	if !since.IsZero() && !until.IsZero() && !since.Before(until) {
+		return nil, fmt.Errorf("since %s is not before until %s", c.Since, c.Until)
+	}
+	if !since.IsZero() || !until.IsZero() {
+		filters = append(filters, CreatedBetween(since, until))
+	}
+
+	if c.MinWords < 0 {
+		return nil, fmt.Errorf("minimum word count must not be negative")
+	}
+	if c.MinWords > 0 {
+		filters = append(filters, MinWords(c.MinWords))
+	}
+
+	// Every required keyword or pattern must match; any excluded one drops the
+	// review. Empty keywords would match every review and are ignored.
+	for _, keyword := range c.Require {
+		if keyword != "" {
+			filters = append(filters, Require("require "+keyword, keywordsPattern([]string{keyword})))
+		}
+	}
+	if exclude := slices.DeleteFunc(slices.Clone(c.Exclude), func(k string) bool { return k == "" }); len(exclude) > 0 {
+		filters = append(filters, Exclude("exclude", keywordsPattern(exclude)))
+	}
+	for _, pattern := range c.RequireRegex {
+		re, err := regexp.Compile(pattern)
+		if err != nil {
+			return nil, fmt.Errorf("invalid require regex: %w", err)
+		}
+		filters = append(filters, Require("require-regex "+pattern, re))
+	}
+	for _, pattern := range c.ExcludeRegex {
+		re, err := regexp.Compile(pattern)
+		if err != nil {
+			return nil, fmt.Errorf("invalid exclude regex: %w", err)
+		}
+		filters = append(filters, Exclude("exclude-regex "+pattern, re))
+	}
+
+	return NewPipeline(filters...), nil
+}
+
+// parseBound parses a date window bound, in UTC. A date as the upper bound
+// includes the whole day.
+func parseBound(value string, upper bool) (time.Time, error) {
+	if value == "" {
+		return time.Time{}, nil
+	}
+	if t, err := time.Parse(time.RFC3339, value); err == nil {
+		return t.UTC(), nil
+	}
+	t, err := time.Parse("2006-01-02", value)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("%q is not a date (2006-01-02) or RFC 3339 time", value)
+	}
+	if upper {
+		t = t.AddDate(0, 0, 1)
+	}
+	return t, nil
+}
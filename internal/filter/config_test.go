@@ -0,0 +1,108 @@
+package filter
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/rizkirmdhnnn/goodreadscrape/internal/models"
+)
+
+func TestConfig_Pipeline(t *testing.T) {
+	tests := []struct {
+		name    string
+		config  Config
+		filters []string
+		wantErr bool
+	}{
+		{"Empty", Config{}, nil, false},
+		{
+			name: "Every filter",
+			config: Config{
+				MinWords:        5,
+				ExcludeSpoilers: true,
+				Since:           "2024-01-01",
+				Until:           "2024-12-31",
+				Require:         []string{"plot", ""},
+				Exclude:         []string{"spam", "ads"},
+				RequireRegex:    []string{`\bending\b`},
+				ExcludeRegex:    []string{`https?://`},
+				Ratings:         []int{4, 5},
+			},
+			filters: []string{"ratings", "no-spoilers", "date", "min-words", "require plot", "exclude", `require-regex \bending\b`, "exclude-regex https?://"},
+		},
+		{"Invalid rating", Config{Ratings: []int{0}}, nil, true},
+		{"Invalid date", Config{Since: "15/01/2024"}, nil, true},
+		{"Empty window", Config{Since: "2024-02-01", Until: "2024-01-31"}, nil, true},
+		{"Negative word count", Config{MinWords: -1}, nil, true},
+		{"Invalid regex", Config{RequireRegex: []string{"("}}, nil, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			pipeline, err := tt.config.Pipeline()
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("Pipeline() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if err != nil {
+				return
+			}
+			var names []string
+			for _, count := range pipeline.Removed() {
+				names = append(names, count.Name)
+			}
+			if !reflect.DeepEqual(names, tt.filters) {
+				t.Errorf("Expected filters %q, got %q", tt.filters, names)
+			}
+		})
+	}
+}
+
+func TestConfig_UntilDateIncludesDay(t *testing.T) {
+	pipeline, err := Config{Since: "2024-01-01", Until: "2024-01-31"}.Pipeline()
+	if err != nil {
+		t.Fatal(err)
+	}
+	reviews := []models.Review{
+		{ReviewID: "first", CreatedAt: time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)},
+		{ReviewID: "last", CreatedAt: time.Date(2024, 1, 31, 23, 59, 0, 0, time.UTC)},
+		{ReviewID: "after", CreatedAt: time.Date(2024, 2, 1, 0, 0, 0, 0, time.UTC)},
+	}
+	if kept := pipeline.Apply(reviews); len(kept) != 2 {
+		t.Errorf("Expected the first and last day kept, got %+v", kept)
+	}
+}
+
+func TestConfig_MergeAndLoad(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "filters.json")
+	data := `{"min_words": 10, "exclude": ["spam"], "ratings": [1, 2], "since": "2024-01-01"}`
+	if err := os.WriteFile(path, []byte(data), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	file, err := LoadConfig(path)
+	if err != nil {
+		t.Fatalf("LoadConfig failed: %v", err)
+	}
+	merged := file.Merge(Config{MinWords: 20, Exclude: []string{"ads"}, ExcludeSpoilers: true})
+
+	expected := Config{
+		MinWords:        20,
+		ExcludeSpoilers: true,
+		Since:           "2024-01-01",
+		Exclude:         []string{"spam", "ads"},
+		Ratings:         []int{1, 2},
+	}
+	if !reflect.DeepEqual(merged, expected) {
+		t.Errorf("Expected %+v, got %+v", expected, merged)
+	}
+
+	if err := os.WriteFile(path, []byte(`{"min_wordz": 10}`), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := LoadConfig(path); err == nil {
+		t.Error("Expected an error for an unknown setting")
+	}
+}
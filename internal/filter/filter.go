@@ -0,0 +1,144 @@
+package filter
+
+import (
+	"regexp"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/rizkirmdhnnn/goodreadscrape/internal/models"
+)
+
+// Filter keeps the reviews Keep returns true for. Name identifies the filter
+// in the counts a Pipeline reports.
+type Filter struct {
+	Name string
+	Keep func(review models.Review) bool
+}
+
+// MinWords keeps reviews whose text has at least n words
+func MinWords(n int) Filter {
+	return Filter{
+		Name: "min-words",
+		Keep: func(r models.Review) bool { return len(strings.Fields(r.ReviewText)) >= n },
+	}
+}
+
+// ExcludeSpoilers drops reviews flagged as containing spoilers
+func ExcludeSpoilers() Filter {
+	return Filter{
+		Name: "no-spoilers",
+		Keep: func(r models.Review) bool { return !r.Spoiler },
+	}
+}
+
+// CreatedBetween keeps reviews created in [from, until). A zero bound leaves
+// that end open. Reviews without a creation time are dropped, as they cannot
+// be placed in the window.
+func CreatedBetween(from time.Time, until time.Time) Filter {
+	return Filter{
+		Name: "date",
+		Keep: func(r models.Review) bool {
+			if r.CreatedAt.IsZero() {
+				return false
+			}
+			if !from.IsZero() && r.CreatedAt.Before(from) {
+				return false
+			}
+			return until.IsZero() || r.CreatedAt.Before(until)
+		},
+	}
+}
+
+// Require keeps reviews whose text matches re
+func Require(name string, re *regexp.Regexp) Filter {
+	return Filter{
+		Name: name,
+		Keep: func(r models.Review) bool { return re.MatchString(r.ReviewText) },
+	}
+}
+
+// Exclude drops reviews whose text matches re
+func Exclude(name string, re *regexp.Regexp) Filter {
+	return Filter{
+		Name: name,
+		Keep: func(r models.Review) bool { return !re.MatchString(r.ReviewText) },
+	}
+}
+
+// Ratings keeps reviews with one of the given star ratings. Unrated reviews
+// are dropped.
+func Ratings(ratings ...int) Filter {
+	return Filter{
+		Name: "ratings",
+		Keep: func(r models.Review) bool { return r.Rating != nil && slices.Contains(ratings, *r.Rating) },
+	}
+}
+
+// keywordsPattern matches any of keywords, ignoring case
+func keywordsPattern(keywords []string) *regexp.Regexp {
+	quoted := make([]string, 0, len(keywords))
+	for _, keyword := range keywords {
+		quoted = append(quoted, regexp.QuoteMeta(keyword))
+	}
+	return regexp.MustCompile(`(?i)` + strings.Join(quoted, "|"))
+}
+
+// Count is the number of reviews a filter removed
+type Count struct {
+	Name    string
+	Removed int
+}
+
+// Pipeline applies filters in order. A review is counted against the first
+// filter that drops it. Pipeline is not safe for concurrent use.
+type Pipeline struct {
+	filters []Filter
+	removed []int
+	seen    int
+}
+
+// NewPipeline creates a pipeline of filters
+func NewPipeline(filters ...Filter) *Pipeline {
+	return &Pipeline{filters: filters, removed: make([]int, len(filters))}
+}
+
+// Len returns the number of filters in the pipeline
+func (p *Pipeline) Len() int {
+	return len(p.filters)
+}
+
+// Apply returns the reviews every filter keeps, in their original order
+func (p *Pipeline) Apply(reviews []models.Review) []models.Review {
+	p.seen += len(reviews)
+	if len(p.filters) == 0 {
+		return reviews
+	}
+
+	kept := make([]models.Review, 0, len(reviews))
+next:
+	for _, review := range reviews {
+		for i, filter := range p.filters {
+			if !filter.Keep(review) {
+				p.removed[i]++
+				continue next
+			}
+		}
+		kept = append(kept, review)
+	}
+	return kept
+}
+
+// Seen returns the number of reviews passed to Apply
+func (p *Pipeline) Seen() int {
+	return p.seen
+}
+
+// Removed returns how many reviews each filter removed, in pipeline order
+func (p *Pipeline) Removed() []Count {
+	counts := make([]Count, 0, len(p.filters))
+	for i, filter := range p.filters {
+		counts = append(counts, Count{Name: filter.Name, Removed: p.removed[i]})
+	}
+	return counts
+}
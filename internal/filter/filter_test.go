@@ -0,0 +1,81 @@
+package filter
+
+import (
+	"reflect"
+	"regexp"
+	"testing"
+	"time"
+
+	"github.com/rizkirmdhnnn/goodreadscrape/internal/models"
+)
+
+func rating(n int) *int {
+	return &n
+}
+
+func TestFilters(t *testing.T) {
+	jan := time.Date(2024, 1, 15, 12, 0, 0, 0, time.UTC)
+	mar := time.Date(2024, 3, 15, 12, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		name   string
+		filter Filter
+		review models.Review
+		keep   bool
+	}{
+		{"Enough words", MinWords(3), models.Review{ReviewText: "one two  three"}, true},
+		{"Too few words", MinWords(3), models.Review{ReviewText: "one two"}, false},
+		{"No spoiler", ExcludeSpoilers(), models.Review{}, true},
+		{"Spoiler", ExcludeSpoilers(), models.Review{Spoiler: true}, false},
+		{"In window", CreatedBetween(jan, mar), models.Review{CreatedAt: jan}, true},
+		{"Window end is exclusive", CreatedBetween(jan, mar), models.Review{CreatedAt: mar}, false},
+		{"Open start", CreatedBetween(time.Time{}, mar), models.Review{CreatedAt: jan}, true},
+		{"Unknown date", CreatedBetween(jan, time.Time{}), models.Review{}, false},
+		{"Required match", Require("require", regexp.MustCompile(`(?i)twist`)), models.Review{ReviewText: "What a Twist"}, true},
+		{"Required missing", Require("require", regexp.MustCompile(`twist`)), models.Review{ReviewText: "Dull"}, false},
+		{"Excluded match", Exclude("exclude", regexp.MustCompile(`spam`)), models.Review{ReviewText: "spam link"}, false},
+		{"Rating listed", Ratings(1, 5), models.Review{Rating: rating(5)}, true},
+		{"Rating not listed", Ratings(1, 5), models.Review{Rating: rating(3)}, false},
+		{"Unrated", Ratings(1, 5), models.Review{}, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.filter.Keep(tt.review); got != tt.keep {
+				t.Errorf("Keep() = %v, want %v", got, tt.keep)
+			}
+		})
+	}
+}
+
+func TestPipeline_CountsRemovals(t *testing.T) {
+	pipeline := NewPipeline(ExcludeSpoilers(), MinWords(2))
+	reviews := []models.Review{
+		{ReviewID: "1", ReviewText: "long enough"},
+		{ReviewID: "2", ReviewText: "short", Spoiler: true}, // Counted against the first filter only
+		{ReviewID: "3", ReviewText: "short"},
+		{ReviewID: "4", ReviewText: "also long enough"},
+	}
+
+	kept := pipeline.Apply(reviews)
+	if len(kept) != 2 || kept[0].ReviewID != "1" || kept[1].ReviewID != "4" {
+		t.Errorf("Expected reviews 1 and 4 in order, got %+v", kept)
+	}
+	pipeline.Apply(reviews[2:3])
+
+	expected := []Count{{Name: "no-spoilers", Removed: 1}, {Name: "min-words", Removed: 2}}
+	if got := pipeline.Removed(); !reflect.DeepEqual(got, expected) {
+		t.Errorf("Expected %+v, got %+v", expected, got)
+	}
+	if pipeline.Seen() != 5 {
+		t.Errorf("Expected 5 reviews seen, got %d", pipeline.Seen())
+	}
+}
+
+func TestPipeline_Empty(t *testing.T) {
+	pipeline := NewPipeline()
+	reviews := []models.Review{{ReviewID: "1"}}
+	if kept := pipeline.Apply(reviews); len(kept) != 1 {
+		t.Errorf("Expected an empty pipeline to keep every review, got %d", len(kept))
+	}
+}
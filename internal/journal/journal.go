@@ -0,0 +1,236 @@
+package journal
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+
+	"github.com/rizkirmdhnnn/goodreadscrape/internal/models"
+)
+
+// Run describes a scraping run, recorded once at the start of its journal so
+// that it can be resumed without repeating its flags
+type Run struct {
+	URLs        []string  `json:"urls"`
+	OutputFiles []string  `json:"output_files"`
+	Format      string    `json:"format,omitempty"`
+	Partition   bool      `json:"partition,omitempty"`
+	Incremental bool      `json:"incremental,omitempty"`
+	StateFile   string    `json:"state_file,omitempty"`
+	Filters     *Filters  `json:"filters,omitempty"`
+	StartedAt   time.Time `json:"started_at"`
+}
+
+// Filters are the review filters of a run. A resumed run keeps them, since
+// saved page tokens only continue the query they came from.
+type Filters struct {
+	Language    string `json:"language,omitempty"`
+	PerLanguage bool   `json:"per_language,omitempty"`
+	Sort        string `json:"sort,omitempty"`
+	Stars       string `json:"stars,omitempty"`
+	TextOnly    bool   `json:"text_only,omitempty"`
+	Search      string `json:"search,omitempty"`
+}
+
+// Entry is the progress of one URL. Later entries for a URL replace earlier ones.
+type Entry struct {
+	URL            string              `json:"url"`
+	Status         models.ScrapeStatus `json:"status"`
+	WorkID         string              `json:"work_id,omitempty"`
+	NextPageToken  string              `json:"next_page_token,omitempty"`
+	ReviewsFetched int                 `json:"reviews_fetched"` // Before client-side filters
+	ReviewsWritten int                 `json:"reviews_written"`
+	BookSaved      bool                `json:"book_saved,omitempty"` // The book's metadata row was written
+	Error          string              `json:"error,omitempty"`
+	UpdatedAt      time.Time           `json:"updated_at"`
+}
+
+// Fetched returns the number of reviews fetched for the URL so far. Journals
+// written before fetched counts were recorded only hold written ones.
+func (e Entry) Fetched() int {
+	return max(e.ReviewsFetched, e.ReviewsWritten)
+}
+
+// line is one line of the journal file, holding either the run or an entry
+type line struct {
+	Run   *Run   `json:"run,omitempty"`
+	Entry *Entry `json:"entry,omitempty"`
+}
+
+// Journal is an append-only JSON Lines log of a run's progress. Every entry
+// is synced to disk as it is recorded, so a run that dies can be resumed from
+// its last recorded state. Journal is safe for concurrent use.
+type Journal struct {
+	mu      sync.Mutex
+	file    *os.File
+	run     Run
+	entries map[string]Entry
+}
+
+// Create starts a new journal at path for run. It fails if the file already
+// exists, since that journal belongs to another run and should be resumed.
+func Create(path string, run Run) (*Journal, error) {
+	dir := filepath.Dir(path)
+	if dir != "." {
+		if err := os.MkdirAll(dir, 0755); err != nil {
+			return nil, fmt.Errorf("failed to create journal directory: %w", err)
+		}
+	}
+
+	file, err := os.OpenFile(path, os.O_WRONLY|os.O_CREATE|os.O_EXCL, 0644)
+	if err != nil {
+		if errors.Is(err, os.ErrExist) {
+			return nil, fmt.Errorf("journal %s already exists; resume it with -resume or choose another path", path)
+		}
+		return nil, fmt.Errorf("failed to create journal: %w", err)
+	}
+
+	j := &Journal{file: file, run: run, entries: make(map[string]Entry)}
+	if err := j.write(line{Run: &run}); err != nil {
+		file.Close()
+		return nil, err
+	}
+	return j, nil
+}
+
+// Open loads an existing journal to resume its run. A partial last line left
+// by an interrupted write is trimmed; new entries are appended after it.
+func Open(path string) (*Journal, error) {
+	file, err := os.OpenFile(path, os.O_RDWR, 0644)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open journal: %w", err)
+	}
+
+	j := &Journal{file: file, entries: make(map[string]Entry)}
+	end, err := j.load()
+	if err != nil {
+		file.Close()
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+	if err := file.Truncate(end); err != nil {
+		file.Close()
+		return nil, fmt.Errorf("failed to trim journal: %w", err)
+	}
+	if _, err := file.Seek(end, io.SeekStart); err != nil {
+		file.Close()
+		return nil, fmt.Errorf("failed to seek journal: %w", err)
+	}
+	return j, nil
+}
+
+// ReadRun returns the run recorded at the start of the journal at path
+func ReadRun(path string) (Run, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return Run{}, fmt.Errorf("failed to open journal: %w", err)
+	}
+	defer file.Close()
+
+	first, err := bufio.NewReader(file).ReadBytes('\n')
+	if err != nil && err != io.EOF {
+		return Run{}, fmt.Errorf("failed to read journal: %w", err)
+	}
+	var l line
+	if err := json.Unmarshal(first, &l); err != nil || l.Run == nil {
+		return Run{}, fmt.Errorf("%s is not a run journal", path)
+	}
+	return *l.Run, nil
+}
+
+// load replays the journal file and returns the offset just past its last
+// complete line
+func (j *Journal) load() (int64, error) {
+	reader := bufio.NewReader(j.file)
+
+	var offset int64
+	first := true
+	for {
+		raw, err := reader.ReadBytes('\n')
+		if err == io.EOF {
+			// A line without its newline was cut short by an interrupted write
+			if first {
+				return 0, fmt.Errorf("not a run journal")
+			}
+			return offset, nil
+		}
+		if err != nil {
+			return 0, fmt.Errorf("failed to read journal: %w", err)
+		}
+
+		var l line
+		if err := json.Unmarshal(bytes.TrimSpace(raw), &l); err != nil {
+			return 0, fmt.Errorf("malformed journal line at offset %d: %w", offset, err)
+		}
+		switch {
+		case first && l.Run != nil:
+			j.run = *l.Run
+		case first:
+			return 0, fmt.Errorf("not a run journal")
+		case l.Entry != nil:
+			j.entries[l.Entry.URL] = *l.Entry
+		}
+		first = false
+		offset += int64(len(raw))
+	}
+}
+
+// Run returns the journal's run
+func (j *Journal) Run() Run {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+	return j.run
+}
+
+// Entry returns the latest entry for url
+func (j *Journal) Entry(url string) (Entry, bool) {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+	entry, ok := j.entries[url]
+	return entry, ok
+}
+
+// Record appends entry to the journal and syncs it to disk
+func (j *Journal) Record(entry Entry) error {
+	if entry.UpdatedAt.IsZero() {
+		entry.UpdatedAt = time.Now().UTC()
+	}
+
+	j.mu.Lock()
+	defer j.mu.Unlock()
+
+	if err := j.write(line{Entry: &entry}); err != nil {
+		return err
+	}
+	j.entries[entry.URL] = entry
+	return nil
+}
+
+// write appends one line and syncs the file. Callers must hold mu, except
+// while the journal is being created.
+func (j *Journal) write(l line) error {
+	data, err := json.Marshal(l)
+	if err != nil {
+		return fmt.Errorf("failed to encode journal entry: %w", err)
+	}
+	if _, err := j.file.Write(append(data, '\n')); err != nil {
+		return fmt.Errorf("failed to write journal: %w", err)
+	}
+	if err := j.file.Sync(); err != nil {
+		return fmt.Errorf("failed to sync journal: %w", err)
+	}
+	return nil
+}
+
+// Close closes the journal file
+func (j *Journal) Close() error {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+	return j.file.Close()
+}
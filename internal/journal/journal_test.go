@@ -0,0 +1,123 @@
+package journal
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/rizkirmdhnnn/goodreadscrape/internal/models"
+)
+
+func TestJournal_RecordAndReplay(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "run.journal.jsonl")
+	run := Run{URLs: []string{"a", "b"}, OutputFiles: []string{"results/run.csv"}}
+
+	j, err := Create(path, run)
+	if err != nil {
+		t.Fatalf("Create failed: %v", err)
+	}
+	entries := []Entry{
+		{URL: "a", Status: models.StatusInProgress},
+		{URL: "a", Status: models.StatusPartial, NextPageToken: "p2", ReviewsWritten: 100},
+		{URL: "b", Status: models.StatusComplete, ReviewsWritten: 3},
+	}
+	for _, entry := range entries {
+		if err := j.Record(entry); err != nil {
+			t.Fatalf("Record failed: %v", err)
+		}
+	}
+	j.Close()
+
+	if _, err := Create(path, run); err == nil {
+		t.Error("Expected error creating over an existing journal")
+	}
+
+	got, err := ReadRun(path)
+	if err != nil {
+		t.Fatalf("ReadRun failed: %v", err)
+	}
+	if len(got.URLs) != 2 || got.OutputFiles[0] != "results/run.csv" {
+		t.Errorf("Unexpected run: %+v", got)
+	}
+
+	j, err = Open(path)
+	if err != nil {
+		t.Fatalf("Open failed: %v", err)
+	}
+	defer j.Close()
+
+	a, _ := j.Entry("a")
+	if a.Status != models.StatusPartial || a.NextPageToken != "p2" || a.ReviewsWritten != 100 {
+		t.Errorf("Expected the latest entry for a, got %+v", a)
+	}
+	if _, ok := j.Entry("c"); ok {
+		t.Error("Expected no entry for a URL never started")
+	}
+}
+
+func TestJournal_TrimsPartialLine(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "run.journal.jsonl")
+
+	j, err := Create(path, Run{URLs: []string{"a"}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	j.Record(Entry{URL: "a", Status: models.StatusPartial, NextPageToken: "p2"})
+	j.Close()
+
+	// Simulate a crash part way through writing an entry
+	file, _ := os.OpenFile(path, os.O_APPEND|os.O_WRONLY, 0644)
+	file.WriteString(`{"entry":{"url":"a","status":"compl`)
+	file.Close()
+
+	j, err = Open(path)
+	if err != nil {
+		t.Fatalf("Open failed: %v", err)
+	}
+	if entry, _ := j.Entry("a"); entry.Status != models.StatusPartial {
+		t.Errorf("Expected the last complete entry, got %+v", entry)
+	}
+	if err := j.Record(Entry{URL: "a", Status: models.StatusComplete}); err != nil {
+		t.Fatalf("Record failed: %v", err)
+	}
+	j.Close()
+
+	j, err = Open(path)
+	if err != nil {
+		t.Fatalf("Open after appending failed: %v", err)
+	}
+	defer j.Close()
+	if entry, _ := j.Entry("a"); entry.Status != models.StatusComplete {
+		t.Errorf("Expected the appended entry, got %+v", entry)
+	}
+}
+
+func TestOpen_RejectsOtherFiles(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "reviews.jsonl")
+	os.WriteFile(path, []byte("{\"review_id\":\"r1\"}\n"), 0644)
+
+	if _, err := Open(path); err == nil {
+		t.Error("Expected error opening a file that is not a run journal")
+	}
+	if _, err := ReadRun(path); err == nil {
+		t.Error("Expected error reading the run of a file that is not a run journal")
+	}
+}
+
+func TestEntry_Fetched(t *testing.T) {
+	tests := []struct {
+		name     string
+		entry    Entry
+		expected int
+	}{
+		{"Fetched and written", Entry{ReviewsFetched: 10, ReviewsWritten: 4}, 10},
+		{"Journal without fetched counts", Entry{ReviewsWritten: 4}, 4},
+		{"Nothing yet", Entry{}, 0},
+	}
+
+	for _, tt := range tests {
+		if got := tt.entry.Fetched(); got != tt.expected {
+			t.Errorf("%s: Fetched() = %d, want %d", tt.name, got, tt.expected)
+		}
+	}
+}
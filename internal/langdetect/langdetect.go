@@ -0,0 +1,276 @@
+// Package langdetect identifies the language of review text offline. Texts
+// in a script used by one language (Greek, Hebrew, Thai, Hangul, Japanese
+// kana) are identified by their script. Scripts shared by several languages
+// (Cyrillic, Arabic, Devanagari, Chinese characters) only suggest their most
+// common language, with low confidence. Latin-script texts are classified by
+// their character trigrams, against profiles built from the small corpora
+// embedded with the package; a text none of them explains well is left
+// undetermined rather than forced into the closest one.
+package langdetect
+
+import (
+	"embed"
+	"math"
+	"path"
+	"strings"
+	"unicode"
+)
+
+//go:embed corpus/*.txt
+var corpusFS embed.FS
+
+// Result is the language detected for a text. Language is empty if it could
+// not be determined, e.g. for an empty text.
+type Result struct {
+	Language   string  // ISO 639-1 code
+	Confidence float64 // 0 to 1
+}
+
+// minLetters is the fewest letters a text needs for its language to be guessed
+const minLetters = 3
+
+// maxEvidence caps the trigrams whose likelihoods count towards confidence.
+// Trigrams are not independent, so the likelihoods of a long text would
+// otherwise make every guess look certain.
+const maxEvidence = 10
+
+// smoothing is added to every trigram count, so trigrams a corpus lacks do
+// not rule its language out
+const smoothing = 0.5
+
+// A text in the language of a profile has most of its trigrams in the
+// profile's corpus. Below minCoverage the best profile is taken to be a
+// different language; up to fullCoverage its confidence is scaled down.
+const (
+	minCoverage  = 0.5
+	fullCoverage = 0.7
+)
+
+// sharedConfidence caps the confidence of a script written in several
+// languages, which only suggests the most common of them
+const sharedConfidence = 0.5
+
+// scriptLanguages maps scripts to the language they are taken to be written
+// in. Shared scripts are written in other languages too.
+var scriptLanguages = []struct {
+	Table    *unicode.RangeTable
+	Language string
+	Shared   bool
+}{
+	{unicode.Cyrillic, "ru", true}, // Also Ukrainian, Bulgarian, Serbian...
+	{unicode.Greek, "el", false},
+	{unicode.Arabic, "ar", true}, // Also Persian, Urdu...
+	{unicode.Hebrew, "he", false},
+	{unicode.Thai, "th", false},
+	{unicode.Hangul, "ko", false},
+	{unicode.Devanagari, "hi", true}, // Also Marathi, Nepali...
+	{unicode.Hiragana, "ja", false},
+	{unicode.Katakana, "ja", false},
+	{unicode.Han, "zh", true}, // Also Japanese written without kana
+}
+
+// profile holds the trigram counts of a language's corpus
+type profile struct {
+	Language string
+	Counts   map[string]int
+	Total    int
+}
+
+// profiles are built once from the embedded corpora
+var profiles, vocabulary = loadProfiles()
+
+// Detect returns the language text is most likely written in
+func Detect(text string) Result {
+	text = strings.ToLower(text)
+
+	// Count the letters of each script; Latin letters are kept for trigrams
+	scripts := make(map[string]int)
+	letters := 0
+	var latin strings.Builder
+	for _, word := range strings.Fields(text) {
+		if strings.Contains(word, "://") || strings.HasPrefix(word, "www.") {
+			continue // Links say nothing about the language
+		}
+		for _, r := range word {
+			if !unicode.IsLetter(r) {
+				latin.WriteByte(' ')
+				continue
+			}
+			letters++
+			if unicode.Is(unicode.Latin, r) {
+				scripts[""]++
+				latin.WriteRune(r)
+				continue
+			}
+			for _, s := range scriptLanguages {
+				if unicode.Is(s.Table, r) {
+					scripts[s.Language]++
+					break
+				}
+			}
+		}
+		latin.WriteByte(' ')
+	}
+	if letters < minLetters {
+		return Result{}
+	}
+
+	// Japanese mixes kana with Chinese characters
+	if scripts["ja"] > 0 {
+		scripts["ja"] += scripts["zh"]
+		delete(scripts, "zh")
+	}
+
+	best, count := "", -1
+	for language, n := range scripts {
+		if n > count || (n == count && language < best) {
+			best, count = language, n
+		}
+	}
+	if best != "" {
+		confidence := float64(count) / float64(letters)
+		if sharedScript(best) {
+			confidence *= sharedConfidence
+		}
+		return Result{Language: best, Confidence: confidence}
+	}
+
+	result := classify(latin.String())
+	result.Confidence *= float64(count) / float64(letters)
+	return result
+}
+
+// classify returns the language whose trigram profile best explains text
+func classify(text string) Result {
+	grams := trigrams(text)
+	if len(grams) == 0 {
+		return Result{}
+	}
+
+	scores := make([]float64, len(profiles))
+	for i, p := range profiles {
+		denominator := float64(p.Total) + smoothing*float64(vocabulary)
+		for _, gram := range grams {
+			scores[i] += math.Log((float64(p.Counts[gram]) + smoothing) / denominator)
+		}
+	}
+
+	// The mean log-likelihood, weighted by the evidence the text provides,
+	// gives the posterior over equally likely languages
+	weight := math.Min(float64(len(grams)), maxEvidence) / float64(len(grams))
+	best, top := 0, math.Inf(-1)
+	for i, score := range scores {
+		scores[i] = score * weight
+		if scores[i] > top {
+			best, top = i, scores[i]
+		}
+	}
+	sum := 0.0
+	for _, score := range scores {
+		sum += math.Exp(score - top)
+	}
+
+	// The posterior only compares the embedded languages, so it is discounted
+	// by how much of the text the best one's corpus covers
+	covered := 0
+	for _, gram := range grams {
+		if profiles[best].Counts[gram] > 0 {
+			covered++
+		}
+	}
+	coverage := float64(covered) / float64(len(grams))
+	if coverage < minCoverage {
+		return Result{}
+	}
+	fit := math.Min((coverage-minCoverage)/(fullCoverage-minCoverage), 1)
+	return Result{Language: profiles[best].Language, Confidence: fit / sum}
+}
+
+// sharedScript reports whether language was detected by a script written in
+// several languages
+func sharedScript(language string) bool {
+	for _, s := range scriptLanguages {
+		if s.Language == language {
+			return s.Shared
+		}
+	}
+	return false
+}
+
+// trigrams returns the character trigrams of the words in text, each word
+// padded with a space on both sides
+func trigrams(text string) []string {
+	var grams []string
+	for _, word := range strings.Fields(text) {
+		runes := []rune(" " + word + " ")
+		for i := 0; i+3 <= len(runes); i++ {
+			grams = append(grams, string(runes[i:i+3]))
+		}
+	}
+	return grams
+}
+
+// loadProfiles builds the trigram profile of every embedded corpus, named
+// <language>.txt, and counts the distinct trigrams across all of them
+func loadProfiles() ([]profile, int) {
+	entries, err := corpusFS.ReadDir("corpus")
+	if err != nil {
+		panic(err)
+	}
+
+	var loaded []profile
+	seen := make(map[string]bool)
+	for _, entry := range entries {
+		data, err := corpusFS.ReadFile(path.Join("corpus", entry.Name()))
+		if err != nil {
+			panic(err)
+		}
+		p := profile{
+			Language: strings.TrimSuffix(entry.Name(), ".txt"),
+			Counts:   make(map[string]int),
+		}
+		for _, gram := range trigrams(lettersOnly(string(data))) {
+			p.Counts[gram]++
+			p.Total++
+			seen[gram] = true
+		}
+		loaded = append(loaded, p)
+	}
+	return loaded, len(seen)
+}
+
+// lettersOnly lowercases text and replaces everything but letters with spaces
+func lettersOnly(text string) string {
+	return strings.Map(func(r rune) rune {
+		if unicode.IsLetter(r) {
+			return unicode.ToLower(r)
+		}
+		return ' '
+	}, text)
+}
+
+// Supports reports whether Detect can identify the language code, ignoring
+// any region or script subtag. Texts in other languages are detected as a
+// related language, with low confidence, or not at all.
+func Supports(code string) bool {
+	primary, _, _ := strings.Cut(code, "-")
+	primary = strings.ToLower(primary)
+	for _, p := range profiles {
+		if p.Language == primary {
+			return true
+		}
+	}
+	for _, s := range scriptLanguages {
+		if s.Language == primary {
+			return true
+		}
+	}
+	return false
+}
+
+// Matches reports whether a detected language is the language code, ignoring
+// case and any region or script subtag (pt-BR matches pt)
+func Matches(code string, detected string) bool {
+	primary, _, _ := strings.Cut(code, "-")
+	return strings.EqualFold(primary, detected)
+}
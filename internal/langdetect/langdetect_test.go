@@ -0,0 +1,150 @@
+package langdetect
+
+import "testing"
+
+// The sentences share no content words with the embedded corpora, so the
+// profiles are tested on text they were not built from
+func TestDetect(t *testing.T) {
+	tests := []struct {
+		text     string
+		expected string
+	}{
+		{"My sister lent me this paperback last summer and I read it during a long train journey.", "en"},
+		{"Kubeli di toko dekat kampus, dan aku menyesal tidak menamatkannya lebih awal.", "id"},
+		{"Lo leí en una tarde, me encantó el final.", "es"},
+		{"Li em dois dias, adorei cada capítulo.", "pt"},
+		{"Trop long et ennuyeux, je ne le recommande à personne.", "fr"},
+		{"Meine Schwester hat mir dieses Taschenbuch geschenkt und ich habe es an einem Wochenende verschlungen.", "de"},
+		{"Mia nonna me lo ha regalato per Natale e l'ho divorato in due sere.", "it"},
+		{"Mijn zus gaf me dit boek voor mijn verjaardag en ik heb het in één weekend verslonden.", "nl"},
+		{"Ένα υπέροχο βιβλίο", "el"},
+		{"とても面白い本でした", "ja"},
+		{"정말 재미있는 책", "ko"},
+		{"See https://example.com/un-libro-bellissimo for my full review of this book", "en"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.expected, func(t *testing.T) {
+			result := Detect(tt.text)
+			if result.Language != tt.expected {
+				t.Errorf("Detect(%q) = %q, want %q", tt.text, result.Language, tt.expected)
+			}
+			if result.Confidence < 0.9 || result.Confidence > 1 {
+				t.Errorf("Expected a confidence between 0.9 and 1 for %q, got %f", tt.text, result.Confidence)
+			}
+		})
+	}
+}
+
+func TestDetectSharedScript(t *testing.T) {
+	tests := []struct {
+		name     string
+		text     string
+		expected string
+	}{
+		{"Russian", "Очень интересная книга", "ru"},
+		{"Ukrainian", "Дуже цікава книжка, раджу всім", "ru"},
+		{"Persian", "کتاب بسیار خوبی بود", "ar"},
+		{"Hindi", "बहुत अच्छी किताब है", "hi"},
+		{"Chinese", "非常好的一本书", "zh"},
+	}
+
+	// Only the most common language of the script is suggested, never confidently
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result := Detect(tt.text)
+			if result.Language != tt.expected {
+				t.Errorf("Detect(%q) = %q, want %q", tt.text, result.Language, tt.expected)
+			}
+			if result.Confidence > sharedConfidence {
+				t.Errorf("Expected a confidence of at most %v for %q, got %f", sharedConfidence, tt.text, result.Confidence)
+			}
+		})
+	}
+}
+
+func TestDetectUnsupportedLatinLanguage(t *testing.T) {
+	tests := []struct {
+		name string
+		text string
+	}{
+		{"Tagalog", "Sobrang ganda ng librong ito, hindi ko mabitawan hanggang matapos ko ang huling kabanata."},
+		{"Swedish", "Jag läste den här boken på semestern och kunde inte sluta förrän jag var klar."},
+		{"Polish", "Przeczytałam tę książkę w jeden weekend i bardzo mi się podobała, polecam wszystkim."},
+		{"Turkish", "Bu kitabı tatilde okudum ve bitirene kadar elimden bırakamadım."},
+		{"Danish", "Jeg læste den på to dage og elskede slutningen."},
+		{"Vietnamese", "Tôi đã đọc cuốn sách này trong kỳ nghỉ và không thể dừng lại cho đến khi đọc xong."},
+	}
+
+	// No embedded profile explains them well, so none is claimed confidently
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if result := Detect(tt.text); result.Confidence >= 0.9 {
+				t.Errorf("Expected no confident language for %q, got %+v", tt.text, result)
+			}
+		})
+	}
+}
+
+func TestDetectUndetermined(t *testing.T) {
+	for _, text := range []string{"", "ok", "5/5 !!!", "https://example.com/review"} {
+		if result := Detect(text); result != (Result{}) {
+			t.Errorf("Expected no language for %q, got %+v", text, result)
+		}
+	}
+}
+
+func TestDetectShortTextIsLessConfident(t *testing.T) {
+	short := Detect("Keren")
+	long := Detect("Keren banget, aku suka sekali dengan alur dan karakternya.")
+	if long.Language != "id" {
+		t.Fatalf("Expected id, got %q", long.Language)
+	}
+	if short.Confidence >= long.Confidence {
+		t.Errorf("Expected a short text to be less certain, got %f for it and %f for a longer one", short.Confidence, long.Confidence)
+	}
+}
+
+func TestSupports(t *testing.T) {
+	tests := []struct {
+		code     string
+		expected bool
+	}{
+		{"id", true},
+		{"EN", true},
+		{"pt-BR", true},
+		{"ru", true},
+		{"ja", true},
+		{"uk", false},
+		{"fa", false},
+		{"tl", false},
+		{"ms", false},
+		{"", false},
+	}
+
+	for _, tt := range tests {
+		if got := Supports(tt.code); got != tt.expected {
+			t.Errorf("Supports(%q) = %v, want %v", tt.code, got, tt.expected)
+		}
+	}
+}
+
+func TestMatches(t *testing.T) {
+	tests := []struct {
+		code     string
+		detected string
+		expected bool
+	}{
+		{"id", "id", true},
+		{"pt-BR", "pt", true},
+		{"EN", "en", true},
+		{"id", "en", false},
+		{"id", "", false},
+	}
+
+	for _, tt := range tests {
+		if got := Matches(tt.code, tt.detected); got != tt.expected {
+			t.Errorf("Matches(%q, %q) = %v, want %v", tt.code, tt.detected, got, tt.expected)
+		}
+	}
+}
@@ -1,32 +1,211 @@
 package models
 
+import "time"
+
 // BookMetadata represents metadata about a book
 type BookMetadata struct {
-	Title         string
-	Author        string
-	AverageRating float64
-	URL           string
+	WorkID           string
+	BookID           string
+	Title            string
+	Author           string
+	Contributors     []Contributor
+	AverageRating    float64
+	RatingsCount     int
+	TextReviewsCount int
+	// RatingDistribution holds the number of 1 to 5 star ratings, in that order
+	RatingDistribution [5]int
+	URL                string
+
+	// Edition details
+	ISBN10         string
+	ISBN13         string
+	ASIN           string
+	Language       string
+	PageCount      int
+	Format         string
+	Publisher      string
+	EditionDate    time.Time // Zero if unknown
+	FirstPublished time.Time // Zero if unknown
+
+	Genres         []string
+	SeriesName     string
+	SeriesPosition string
+	CoverURL       string
+	Description    string
+}
+
+// Contributor represents a person credited on a book, e.g. an author or translator
+type Contributor struct {
+	ID   string
+	Name string
+	Role string
+	URL  string
 }
 
 // Filters contains filtering options for scraping
 type Filters struct {
-	Language string
+	// Languages to fetch reviews in, each with queries of its own; reviews are
+	// tagged with the language they were fetched under. Empty fetches reviews
+	// in every language, untagged.
+	Languages []string
+	// MaxPerLanguage applies the review limit to each language rather than to
+	// all of them together
+	MaxPerLanguage bool
+
+	Sort ReviewSort
+
+	// Star ratings to fetch, 1-5; 0 leaves that end of the range open
+	MinRating int
+	MaxRating int
+
+	TextOnly bool   // Leave out ratings without review text
+	Search   string // Only reviews matching this search term
+
+	// After continues fetching reviews from a GraphQL page token saved by an
+	// earlier run (see BookData.NextPageToken); empty starts from the first page
+	After string
+
+	// Known holds the reviews saved by earlier runs. If non-nil, reviews are
+	// fetched newest first, whatever Sort is, and fetching stops at the first
+	// one Known has seen, so only reviews newer than the last run are returned.
+	Known KnownReviews
+}
+
+// ReviewSort is the order in which a book's reviews are fetched
+type ReviewSort string
+
+// Review sort orders
+const (
+	SortDefault ReviewSort = ""       // Goodreads' default order, most popular first
+	SortNewest  ReviewSort = "newest" // Most recently created first
+	SortOldest  ReviewSort = "oldest" // Least recently created first
+)
+
+// KnownReviews indexes the reviews saved by earlier runs by work ID
+type KnownReviews map[string]*KnownWork
+
+// KnownWork is what is known about the saved reviews of one work
+type KnownWork struct {
+	ReviewIDs map[string]bool
+
+	// Latest is the CreatedAt of the newest saved review fetched under each
+	// language code, or "" for reviews fetched in every language. Languages
+	// are fetched one after the other, so each catches up on its own.
+	Latest map[string]time.Time
+}
+
+// Work returns what is known about a work, adding it if there is nothing yet
+func (k KnownReviews) Work(workID string) *KnownWork {
+	work, ok := k[workID]
+	if !ok {
+		work = &KnownWork{ReviewIDs: make(map[string]bool), Latest: make(map[string]time.Time)}
+		k[workID] = work
+	}
+	return work
+}
+
+// Add records a saved review of a work, fetched under language
+func (k KnownReviews) Add(workID string, reviewID string, language string, createdAt time.Time) {
+	if workID == "" || reviewID == "" {
+		return
+	}
+	work := k.Work(workID)
+	work.ReviewIDs[reviewID] = true
+	work.RaiseLatest(language, createdAt)
+}
+
+// RaiseLatest moves Latest of language up to createdAt if that is newer
+func (w *KnownWork) RaiseLatest(language string, createdAt time.Time) {
+	if createdAt.After(w.Latest[language]) {
+		w.Latest[language] = createdAt
+	}
+}
+
+// Seen reports whether a review was saved before, or is older than the newest
+// saved review of its work fetched under the same language
+func (k KnownReviews) Seen(review Review) bool {
+	work, ok := k[review.WorkID]
+	if !ok {
+		return false
+	}
+	if work.ReviewIDs[review.ReviewID] {
+		return true
+	}
+	return !review.CreatedAt.IsZero() && review.CreatedAt.Before(work.Latest[review.FetchedLanguage])
 }
 
 // BookData contains complete book information including metadata and reviews
 type BookData struct {
-	Metadata BookMetadata
-	Reviews  []Review
+	Metadata  BookMetadata
+	Reviews   []Review
+	ScrapedAt time.Time
+
+	// NextPageToken is the GraphQL page token to continue fetching reviews
+	// after the last one in Reviews; empty once every review was fetched
+	NextPageToken string
+
+	// ReviewsFetched is the number of reviews fetched before client-side
+	// filters dropped some of Reviews; zero if none were applied
+	ReviewsFetched int
+}
+
+// Fetched returns the number of reviews fetched for the book, before
+// client-side filters
+func (b BookData) Fetched() int {
+	return max(b.ReviewsFetched, len(b.Reviews))
 }
 
+// ScrapeStatus is the outcome of scraping one book
+type ScrapeStatus string
+
+// Scrape statuses
+const (
+	StatusInProgress ScrapeStatus = "in_progress" // Being scraped; the run stopped before it finished
+	StatusComplete   ScrapeStatus = "complete"    // Every review wanted was fetched and saved
+	StatusPartial    ScrapeStatus = "partial"     // Stopped early after saving some reviews
+	StatusFailed     ScrapeStatus = "failed"      // Stopped before any review was saved
+)
+
 // Review represents a single book review
 type Review struct {
+	WorkID       string
 	BookURL      string
 	BookTitle    string
 	ReviewID     string
+	ReviewURL    string
 	ReviewerName string
-	Rating       string
+	Rating       *int // 1-5, nil if the reviewer didn't rate the book
 	ReviewText   string
-	ReviewDate   string
-	Language     string
+
+	// Language is detected from the review text, with a confidence from 0 to
+	// 1; empty if undetermined. FetchedLanguage is the language code the
+	// review was fetched under, if any, which Goodreads sometimes gets wrong.
+	Language           string
+	LanguageConfidence float64
+	FetchedLanguage    string
+
+	// Timestamps, in UTC; zero if unknown
+	CreatedAt      time.Time
+	UpdatedAt      time.Time
+	LastRevisionAt time.Time
+
+	// Reviewer identity
+	ReviewerID               string
+	ReviewerURL              string
+	ReviewerIsAuthor         bool
+	ReviewerFollowersCount   int
+	ReviewerTextReviewsCount int
+
+	// Engagement and status
+	LikeCount    int
+	CommentCount int
+	Spoiler      bool
+	Shelf        string
+	Tags         []ReviewTag
+}
+
+// ReviewTag is a user tag (custom shelf) attached to a review
+type ReviewTag struct {
+	Name string
+	URL  string
 }
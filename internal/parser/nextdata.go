@@ -0,0 +1,355 @@
+package parser
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	stdhtml "html"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/PuerkitoBio/goquery"
+	"github.com/rizkirmdhnnn/goodreadscrape/internal/models"
+)
+
+// ErrNoNextData is returned when a page has no __NEXT_DATA__ script
+var ErrNoNextData = errors.New("__NEXT_DATA__ not found")
+
+// nextData is the subset of the Next.js __NEXT_DATA__ blob that holds the Apollo cache
+type nextData struct {
+	Props struct {
+		PageProps struct {
+			ApolloState map[string]json.RawMessage `json:"apolloState"`
+		} `json:"pageProps"`
+	} `json:"props"`
+}
+
+// ApolloRef is a normalized reference to another entry in the Apollo cache
+type ApolloRef struct {
+	Ref string `json:"__ref"`
+}
+
+// ContributorEdge links a book to a contributor with a role (Author, Translator, ...)
+type ContributorEdge struct {
+	Node ApolloRef `json:"node"`
+	Role string    `json:"role"`
+}
+
+// ApolloBook is a Book entry in the Apollo cache
+type ApolloBook struct {
+	ID                        string            `json:"id"`
+	LegacyID                  int64             `json:"legacyId"`
+	Title                     string            `json:"title"`
+	TitleComplete             string            `json:"titleComplete"`
+	WebURL                    string            `json:"webUrl"`
+	ImageURL                  string            `json:"imageUrl"`
+	Description               string            `json:"description"`
+	PrimaryContributorEdge    *ContributorEdge  `json:"primaryContributorEdge"`
+	SecondaryContributorEdges []ContributorEdge `json:"secondaryContributorEdges"`
+	BookSeries                []BookSeries      `json:"bookSeries"`
+	BookGenres                []BookGenre       `json:"bookGenres"`
+	Details                   BookDetails       `json:"details"`
+	Work                      *ApolloRef        `json:"work"`
+}
+
+// BookDetails holds edition-level details of a book
+type BookDetails struct {
+	ASIN            string  `json:"asin"`
+	ISBN            string  `json:"isbn"`
+	ISBN13          string  `json:"isbn13"`
+	Format          string  `json:"format"`
+	NumPages        int     `json:"numPages"`
+	PublicationTime float64 `json:"publicationTime"` // Epoch milliseconds
+	Publisher       string  `json:"publisher"`
+	Language        struct {
+		Name string `json:"name"`
+	} `json:"language"`
+}
+
+// BookSeries links a book to a series with its position in it
+type BookSeries struct {
+	UserPosition string    `json:"userPosition"`
+	Series       ApolloRef `json:"series"`
+}
+
+// BookGenre is a genre attached to a book
+type BookGenre struct {
+	Genre struct {
+		Name   string `json:"name"`
+		WebURL string `json:"webUrl"`
+	} `json:"genre"`
+}
+
+// ApolloSeries is a Series entry in the Apollo cache
+type ApolloSeries struct {
+	ID     string `json:"id"`
+	Title  string `json:"title"`
+	WebURL string `json:"webUrl"`
+}
+
+// ApolloContributor is a Contributor entry in the Apollo cache
+type ApolloContributor struct {
+	ID       string `json:"id"`
+	LegacyID int64  `json:"legacyId"`
+	Name     string `json:"name"`
+	WebURL   string `json:"webUrl"`
+}
+
+// WorkStats holds the aggregate rating statistics of a work
+type WorkStats struct {
+	AverageRating    float64 `json:"averageRating"`
+	RatingsCount     int     `json:"ratingsCount"`
+	RatingsCountDist []int   `json:"ratingsCountDist"` // Counts for 1 to 5 stars
+	TextReviewsCount int     `json:"textReviewsCount"`
+}
+
+// WorkDetails holds work-level details shared by all editions
+type WorkDetails struct {
+	OriginalTitle   string  `json:"originalTitle"`
+	PublicationTime float64 `json:"publicationTime"` // Epoch milliseconds of first publication
+}
+
+// ApolloWork is a Work entry in the Apollo cache
+type ApolloWork struct {
+	ID       string      `json:"id"`
+	LegacyID int64       `json:"legacyId"`
+	Details  WorkDetails `json:"details"`
+	Stats    WorkStats   `json:"stats"`
+}
+
+// Contributor is a resolved contributor of a book together with its role
+type Contributor struct {
+	ID     string
+	Name   string
+	Role   string
+	WebURL string
+}
+
+// BookState is the book-level data decoded from a page's Apollo state
+type BookState struct {
+	Book         ApolloBook
+	Work         ApolloWork
+	Contributors []Contributor
+	Series       *ApolloSeries
+	// SeriesPosition is the book's position in Series, e.g. "1" or "2.5"
+	SeriesPosition string
+	// Description is the plain-text book description
+	Description string
+}
+
+// ParseBookState decodes the __NEXT_DATA__ Apollo state of a book page and
+// resolves the book, its work and its contributors
+func ParseBookState(doc *goquery.Document) (*BookState, error) {
+	script := doc.Find("script#__NEXT_DATA__").First()
+	if script.Length() == 0 {
+		return nil, ErrNoNextData
+	}
+
+	var data nextData
+	if err := json.Unmarshal([]byte(script.Text()), &data); err != nil {
+		return nil, fmt.Errorf("failed to decode __NEXT_DATA__: %w", err)
+	}
+
+	apollo := data.Props.PageProps.ApolloState
+	if len(apollo) == 0 {
+		return nil, fmt.Errorf("apolloState missing from __NEXT_DATA__")
+	}
+
+	bookKey := findBookKey(apollo)
+	if bookKey == "" {
+		return nil, fmt.Errorf("no Book entry in apolloState")
+	}
+
+	state := &BookState{}
+	if err := json.Unmarshal(apollo[bookKey], &state.Book); err != nil {
+		return nil, fmt.Errorf("failed to decode %s: %w", bookKey, err)
+	}
+
+	if state.Book.Work != nil {
+		if raw, ok := apollo[state.Book.Work.Ref]; ok {
+			if err := json.Unmarshal(raw, &state.Work); err != nil {
+				return nil, fmt.Errorf("failed to decode %s: %w", state.Book.Work.Ref, err)
+			}
+		}
+		// The ref itself carries the ID even if the entry was not cached
+		if state.Work.ID == "" {
+			state.Work.ID = strings.TrimPrefix(state.Book.Work.Ref, "Work:")
+		}
+	}
+
+	state.Description = bookDescription(apollo[bookKey], state.Book.Description)
+
+	if len(state.Book.BookSeries) > 0 {
+		entry := state.Book.BookSeries[0]
+		if raw, ok := apollo[entry.Series.Ref]; ok {
+			var series ApolloSeries
+			if err := json.Unmarshal(raw, &series); err != nil {
+				return nil, fmt.Errorf("failed to decode %s: %w", entry.Series.Ref, err)
+			}
+			state.Series = &series
+			state.SeriesPosition = entry.UserPosition
+		}
+	}
+
+	var edges []ContributorEdge
+	if state.Book.PrimaryContributorEdge != nil {
+		edges = append(edges, *state.Book.PrimaryContributorEdge)
+	}
+	edges = append(edges, state.Book.SecondaryContributorEdges...)
+
+	for _, edge := range edges {
+		raw, ok := apollo[edge.Node.Ref]
+		if !ok {
+			continue
+		}
+		var c ApolloContributor
+		if err := json.Unmarshal(raw, &c); err != nil {
+			return nil, fmt.Errorf("failed to decode %s: %w", edge.Node.Ref, err)
+		}
+		state.Contributors = append(state.Contributors, Contributor{
+			ID:     c.ID,
+			Name:   strings.TrimSpace(c.Name),
+			Role:   edge.Role,
+			WebURL: c.WebURL,
+		})
+	}
+
+	return state, nil
+}
+
+// Metadata converts the decoded state into models.BookMetadata
+func (state *BookState) Metadata(bookURL string) models.BookMetadata {
+	metadata := models.BookMetadata{
+		WorkID:           state.Work.ID,
+		Title:            strings.TrimSpace(state.Book.Title),
+		Author:           "Unknown Author",
+		AverageRating:    state.Work.Stats.AverageRating,
+		RatingsCount:     state.Work.Stats.RatingsCount,
+		TextReviewsCount: state.Work.Stats.TextReviewsCount,
+		URL:              bookURL,
+	}
+
+	// Edition details
+	details := state.Book.Details
+	metadata.ISBN10 = details.ISBN
+	metadata.ISBN13 = details.ISBN13
+	metadata.ASIN = details.ASIN
+	metadata.Language = details.Language.Name
+	metadata.PageCount = details.NumPages
+	metadata.Format = details.Format
+	metadata.Publisher = details.Publisher
+	metadata.EditionDate = EpochMillisToTime(details.PublicationTime)
+	metadata.FirstPublished = EpochMillisToTime(state.Work.Details.PublicationTime)
+
+	for i, count := range state.Work.Stats.RatingsCountDist {
+		if i < len(metadata.RatingDistribution) {
+			metadata.RatingDistribution[i] = count
+		}
+	}
+	for _, genre := range state.Book.BookGenres {
+		if name := strings.TrimSpace(genre.Genre.Name); name != "" {
+			metadata.Genres = append(metadata.Genres, name)
+		}
+	}
+	if state.Series != nil {
+		metadata.SeriesName = state.Series.Title
+		metadata.SeriesPosition = state.SeriesPosition
+	}
+	metadata.CoverURL = state.Book.ImageURL
+	metadata.Description = state.Description
+
+	if state.Book.LegacyID > 0 {
+		metadata.BookID = strconv.FormatInt(state.Book.LegacyID, 10)
+	} else {
+		metadata.BookID = BookIDFromURL(bookURL)
+	}
+	if metadata.Title == "" {
+		metadata.Title = "Unknown Title"
+	}
+
+	for _, c := range state.Contributors {
+		metadata.Contributors = append(metadata.Contributors, models.Contributor{
+			ID:   c.ID,
+			Name: c.Name,
+			Role: c.Role,
+			URL:  c.WebURL,
+		})
+	}
+	// The primary contributor comes first
+	if len(metadata.Contributors) > 0 {
+		metadata.Author = metadata.Contributors[0].Name
+	}
+
+	return metadata
+}
+
+// EpochMillisToTime converts an epoch millisecond timestamp to UTC, returning the zero time for 0
+func EpochMillisToTime(ms float64) time.Time {
+	if ms == 0 {
+		return time.Time{}
+	}
+	return time.UnixMilli(int64(ms)).UTC()
+}
+
+// htmlTagRegex matches HTML tags in descriptions
+var htmlTagRegex = regexp.MustCompile(`<[^>]*>`)
+
+// bookDescription prefers the pre-stripped description variant Goodreads caches
+// alongside the HTML one, and strips tags from the HTML one otherwise
+func bookDescription(rawBook json.RawMessage, htmlDescription string) string {
+	var fields map[string]json.RawMessage
+	if err := json.Unmarshal(rawBook, &fields); err == nil {
+		for key, value := range fields {
+			if !strings.HasPrefix(key, "description(") || !strings.Contains(key, `"stripped":true`) {
+				continue
+			}
+			var stripped string
+			if err := json.Unmarshal(value, &stripped); err == nil && stripped != "" {
+				return strings.TrimSpace(stripped)
+			}
+		}
+	}
+
+	text := strings.NewReplacer("<br>", "\n", "<br/>", "\n", "<br />", "\n").Replace(htmlDescription)
+	return strings.TrimSpace(stdhtml.UnescapeString(htmlTagRegex.ReplaceAllString(text, "")))
+}
+
+// findBookKey returns the cache key of the page's main book. It prefers the
+// ROOT_QUERY lookup and otherwise falls back to the first Book with a work.
+func findBookKey(apollo map[string]json.RawMessage) string {
+	if raw, ok := apollo["ROOT_QUERY"]; ok {
+		var root map[string]json.RawMessage
+		if err := json.Unmarshal(raw, &root); err == nil {
+			for key, value := range root {
+				if !strings.HasPrefix(key, "getBookByLegacyId") {
+					continue
+				}
+				var ref ApolloRef
+				if err := json.Unmarshal(value, &ref); err == nil {
+					if _, ok := apollo[ref.Ref]; ok {
+						return ref.Ref
+					}
+				}
+			}
+		}
+	}
+
+	keys := make([]string, 0, len(apollo))
+	for key := range apollo {
+		if strings.HasPrefix(key, "Book:") {
+			keys = append(keys, key)
+		}
+	}
+	sort.Strings(keys)
+
+	for _, key := range keys {
+		var book ApolloBook
+		if err := json.Unmarshal(apollo[key], &book); err == nil && book.Work != nil && book.Title != "" {
+			return key
+		}
+	}
+	return ""
+}
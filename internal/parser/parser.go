@@ -56,3 +56,27 @@ func ExtractBookMetadata(doc *goquery.Document, bookURL string) (string, string,
 	return title, author, avgRating
 }
 
+// workIDRegex matches the work reference in the page's embedded Apollo state
+var workIDRegex = regexp.MustCompile(`"work":\s*{\s*"__ref":\s*"Work:(kca://work/[^"]+)"`)
+
+// ExtractWorkID finds the work ID in the JavaScript embedded in a book page.
+// It returns an empty string if none is found.
+func ExtractWorkID(html []byte) string {
+	matches := workIDRegex.FindSubmatch(html)
+	if len(matches) > 1 {
+		return string(matches[1])
+	}
+	return ""
+}
+
+// bookIDRegex matches the numeric book ID in a /book/show/ URL
+var bookIDRegex = regexp.MustCompile(`/book/show/(\d+)`)
+
+// BookIDFromURL returns the numeric Goodreads book ID in bookURL, or an empty string
+func BookIDFromURL(bookURL string) string {
+	matches := bookIDRegex.FindStringSubmatch(bookURL)
+	if len(matches) > 1 {
+		return matches[1]
+	}
+	return ""
+}
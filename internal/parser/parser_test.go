@@ -1,6 +1,10 @@
 package parser
 
 import (
+	"errors"
+	"os"
+	"path/filepath"
+	"reflect"
 	"strings"
 	"testing"
 
@@ -132,3 +136,171 @@ func TestExtractBookMetadata(t *testing.T) {
 		})
 	}
 }
+
+func TestExtractWorkID(t *testing.T) {
+	tests := []struct {
+		name     string
+		html     string
+		expected string
+	}{
+		{
+			name:     "Work reference in Apollo state",
+			html:     `<script id="__NEXT_DATA__">{"work": {"__ref": "Work:kca://work/amzn1.gr.work.v1.abc"}}</script>`,
+			expected: "kca://work/amzn1.gr.work.v1.abc",
+		},
+		{
+			name:     "No work reference",
+			html:     `<html><body>No state here</body></html>`,
+			expected: "",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := ExtractWorkID([]byte(tt.html)); got != tt.expected {
+				t.Errorf("ExtractWorkID() = %q, want %q", got, tt.expected)
+			}
+		})
+	}
+}
+
+func TestParseBookState(t *testing.T) {
+	doc := loadFixture(t, "book_page.html")
+
+	state, err := ParseBookState(doc)
+	if err != nil {
+		t.Fatalf("ParseBookState failed: %v", err)
+	}
+
+	if state.Book.Title != "Laskar Pelangi" {
+		t.Errorf("Expected title 'Laskar Pelangi', got %q", state.Book.Title)
+	}
+	if state.Book.LegacyID != 1362193 {
+		t.Errorf("Expected book legacy ID 1362193, got %d", state.Book.LegacyID)
+	}
+	if state.Work.ID != "kca://work/amzn1.gr.work.v1.LPW" {
+		t.Errorf("Unexpected work ID: %q", state.Work.ID)
+	}
+	if state.Work.Stats.AverageRating != 4.23 {
+		t.Errorf("Expected rating 4.23, got %f", state.Work.Stats.AverageRating)
+	}
+	if state.Work.Stats.RatingsCount != 88517 {
+		t.Errorf("Expected ratings count 88517, got %d", state.Work.Stats.RatingsCount)
+	}
+	if state.Work.Stats.TextReviewsCount != 6433 {
+		t.Errorf("Expected text reviews count 6433, got %d", state.Work.Stats.TextReviewsCount)
+	}
+
+	expectedContributors := []Contributor{
+		{ID: "kca://author/amzn1.gr.author.v1.AH", Name: "Andrea Hirata", Role: "Author", WebURL: "https://www.goodreads.com/author/show/1015453.Andrea_Hirata"},
+		{ID: "kca://author/amzn1.gr.author.v1.AK", Name: "Angie Kilbane", Role: "Translator", WebURL: "https://www.goodreads.com/author/show/3322110.Angie_Kilbane"},
+	}
+	if !reflect.DeepEqual(state.Contributors, expectedContributors) {
+		t.Errorf("Contributors = %+v, want %+v", state.Contributors, expectedContributors)
+	}
+}
+
+func TestParseBookStateDetails(t *testing.T) {
+	doc := loadFixture(t, "book_page.html")
+
+	state, err := ParseBookState(doc)
+	if err != nil {
+		t.Fatalf("ParseBookState failed: %v", err)
+	}
+
+	details := state.Book.Details
+	if details.ISBN != "9793062797" || details.ISBN13 != "9789793062792" || details.ASIN != "B0BXYZ1234" {
+		t.Errorf("Unexpected identifiers: ISBN %q, ISBN13 %q, ASIN %q", details.ISBN, details.ISBN13, details.ASIN)
+	}
+	if details.Language.Name != "Indonesian" {
+		t.Errorf("Expected language 'Indonesian', got %q", details.Language.Name)
+	}
+	if details.NumPages != 529 || details.Format != "Paperback" || details.Publisher != "Bentang Pustaka" {
+		t.Errorf("Unexpected edition details: %+v", details)
+	}
+	if details.PublicationTime != 1199174400000 {
+		t.Errorf("Expected edition publication time 1199174400000, got %f", details.PublicationTime)
+	}
+	if state.Work.Details.PublicationTime != 1125532800000 {
+		t.Errorf("Expected first publication time 1125532800000, got %f", state.Work.Details.PublicationTime)
+	}
+
+	var genres []string
+	for _, g := range state.Book.BookGenres {
+		genres = append(genres, g.Genre.Name)
+	}
+	expectedGenres := []string{"Fiction", "Indonesian Literature", "Education"}
+	if !reflect.DeepEqual(genres, expectedGenres) {
+		t.Errorf("Genres = %v, want %v", genres, expectedGenres)
+	}
+
+	if state.Series == nil || state.Series.Title != "Tetralogi Laskar Pelangi" || state.SeriesPosition != "1" {
+		t.Errorf("Unexpected series: %+v position %q", state.Series, state.SeriesPosition)
+	}
+
+	if !strings.HasSuffix(state.Book.ImageURL, "/1362193.jpg") {
+		t.Errorf("Unexpected cover URL: %q", state.Book.ImageURL)
+	}
+
+	expectedDescription := "Laskar Pelangi tells the story of ten children in Belitung & their teachers."
+	if state.Description != expectedDescription {
+		t.Errorf("Description = %q, want %q", state.Description, expectedDescription)
+	}
+
+	expectedDist := []int{1208, 2563, 11397, 29860, 43489}
+	if !reflect.DeepEqual(state.Work.Stats.RatingsCountDist, expectedDist) {
+		t.Errorf("RatingsCountDist = %v, want %v", state.Work.Stats.RatingsCountDist, expectedDist)
+	}
+}
+
+func TestBookDescriptionStripsHTML(t *testing.T) {
+	raw := []byte(`{"description": "<b>Bold</b> start<br />next &amp; last"}`)
+	got := bookDescription(raw, "<b>Bold</b> start<br />next &amp; last")
+	if got != "Bold start\nnext & last" {
+		t.Errorf("bookDescription() = %q", got)
+	}
+}
+
+func TestParseBookStateMissing(t *testing.T) {
+	doc, err := goquery.NewDocumentFromReader(strings.NewReader(`<html><body><a data-testid="title">Only markup</a></body></html>`))
+	if err != nil {
+		t.Fatalf("Failed to parse HTML: %v", err)
+	}
+
+	if _, err := ParseBookState(doc); !errors.Is(err, ErrNoNextData) {
+		t.Errorf("Expected ErrNoNextData, got %v", err)
+	}
+}
+
+func TestBookIDFromURL(t *testing.T) {
+	tests := []struct {
+		url      string
+		expected string
+	}{
+		{"https://www.goodreads.com/book/show/1362193.Laskar_Pelangi", "1362193"},
+		{"https://www.goodreads.com/book/show/54321?ref=nav_som", "54321"},
+		{"https://www.goodreads.com/author/show/12345", ""},
+	}
+
+	for _, tt := range tests {
+		if got := BookIDFromURL(tt.url); got != tt.expected {
+			t.Errorf("BookIDFromURL(%q) = %q, want %q", tt.url, got, tt.expected)
+		}
+	}
+}
+
+// loadFixture parses an HTML file from testdata
+func loadFixture(t *testing.T, name string) *goquery.Document {
+	t.Helper()
+	file, err := os.Open(filepath.Join("testdata", name))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer file.Close()
+
+	doc, err := goquery.NewDocumentFromReader(file)
+	if err != nil {
+		t.Fatalf("Failed to parse fixture %s: %v", name, err)
+	}
+	return doc
+}
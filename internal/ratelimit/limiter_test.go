@@ -0,0 +1,63 @@
+package ratelimit
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestLimiterBurst(t *testing.T) {
+	l := NewLimiter(10, 3)
+
+	start := time.Now()
+	for i := 0; i < 3; i++ {
+		if err := l.Wait(context.Background()); err != nil {
+			t.Fatalf("Wait failed: %v", err)
+		}
+	}
+	if elapsed := time.Since(start); elapsed > 50*time.Millisecond {
+		t.Errorf("Expected burst of 3 to pass immediately, took %s", elapsed)
+	}
+
+	// The fourth request has to wait roughly 1/rate
+	start = time.Now()
+	if err := l.Wait(context.Background()); err != nil {
+		t.Fatalf("Wait failed: %v", err)
+	}
+	if elapsed := time.Since(start); elapsed < 50*time.Millisecond {
+		t.Errorf("Expected fourth request to be throttled, took %s", elapsed)
+	}
+}
+
+func TestLimiterDisabled(t *testing.T) {
+	var nilLimiter *Limiter
+	if err := nilLimiter.Wait(context.Background()); err != nil {
+		t.Errorf("nil limiter returned error: %v", err)
+	}
+
+	l := NewLimiter(0, 1)
+	start := time.Now()
+	for i := 0; i < 100; i++ {
+		if err := l.Wait(context.Background()); err != nil {
+			t.Fatalf("Wait failed: %v", err)
+		}
+	}
+	if elapsed := time.Since(start); elapsed > 50*time.Millisecond {
+		t.Errorf("Expected disabled limiter not to block, took %s", elapsed)
+	}
+}
+
+func TestLimiterCancel(t *testing.T) {
+	l := NewLimiter(0.1, 1)
+	if err := l.Wait(context.Background()); err != nil {
+		t.Fatalf("Wait failed: %v", err)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+
+	if err := l.Wait(ctx); !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("Expected DeadlineExceeded, got %v", err)
+	}
+}
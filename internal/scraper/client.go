@@ -5,9 +5,10 @@ import (
 	"time"
 )
 
-// HTTPClient interface for making HTTP requests
+// HTTPClient interface for making HTTP requests. *http.Client satisfies it,
+// so custom transports, proxies and test doubles can be plugged in directly.
 type HTTPClient interface {
-	Get(url string) (*http.Response, error)
+	Do(req *http.Request) (*http.Response, error)
 }
 
 // defaultHTTPClient provides a configured HTTP client with timeouts
@@ -24,9 +25,9 @@ func NewHTTPClient() HTTPClient {
 	}
 }
 
-// Get performs an HTTP GET request
-func (c *defaultHTTPClient) Get(url string) (*http.Response, error) {
-	return c.client.Get(url)
+// Do sends an HTTP request and returns its response
+func (c *defaultHTTPClient) Do(req *http.Request) (*http.Response, error) {
+	return c.client.Do(req)
 }
 
 // GRPC Client interface for gRPC communication
@@ -0,0 +1,195 @@
+package scraper
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Error categories reported by ErrorCategory
+const (
+	CategoryNotFound      = "not_found"
+	CategoryRateLimited   = "rate_limited"
+	CategoryBlocked       = "blocked"
+	CategoryAuth          = "auth"
+	CategorySchemaChanged = "schema_changed"
+	CategoryNetwork       = "network"
+	CategoryCancelled     = "cancelled"
+	CategoryOther         = "other"
+)
+
+// NotFoundError is returned when a Goodreads page or resource does not exist
+type NotFoundError struct {
+	URL string
+}
+
+func (e *NotFoundError) Error() string {
+	return fmt.Sprintf("not found: %s", e.URL)
+}
+
+// RateLimitedError is returned when Goodreads or the GraphQL API responds with 429
+type RateLimitedError struct {
+	URL        string
+	RetryAfter time.Duration
+}
+
+func (e *RateLimitedError) Error() string {
+	if e.RetryAfter > 0 {
+		return fmt.Sprintf("rate limited: %s (retry after %s)", e.URL, e.RetryAfter)
+	}
+	return fmt.Sprintf("rate limited: %s", e.URL)
+}
+
+// BlockedError is returned when the request was refused or answered with a captcha page
+type BlockedError struct {
+	URL        string
+	StatusCode int
+	Reason     string
+}
+
+func (e *BlockedError) Error() string {
+	return fmt.Sprintf("blocked (%d %s): %s", e.StatusCode, e.Reason, e.URL)
+}
+
+// AuthError is returned when the API key is missing or rejected
+type AuthError struct {
+	Message string
+}
+
+func (e *AuthError) Error() string {
+	return fmt.Sprintf("authentication failed: %s", e.Message)
+}
+
+// SchemaChangedError is returned when a page or API response no longer has the expected structure
+type SchemaChangedError struct {
+	What string
+	Err  error
+}
+
+func (e *SchemaChangedError) Error() string {
+	if e.Err != nil {
+		return fmt.Sprintf("unexpected %s: %v", e.What, e.Err)
+	}
+	return fmt.Sprintf("unexpected %s", e.What)
+}
+
+func (e *SchemaChangedError) Unwrap() error {
+	return e.Err
+}
+
+// NetworkError is returned for transport failures and server-side (5xx) errors
+type NetworkError struct {
+	URL        string
+	Err        error
+	RetryAfter time.Duration
+}
+
+func (e *NetworkError) Error() string {
+	return fmt.Sprintf("network error for %s: %v", e.URL, e.Err)
+}
+
+func (e *NetworkError) Unwrap() error {
+	return e.Err
+}
+
+// ErrorCategory classifies err into one of the Category* constants
+func ErrorCategory(err error) string {
+	var (
+		notFound    *NotFoundError
+		rateLimited *RateLimitedError
+		blocked     *BlockedError
+		auth        *AuthError
+		schema      *SchemaChangedError
+		network     *NetworkError
+	)
+
+	switch {
+	case err == nil:
+		return ""
+	case errors.Is(err, context.Canceled), errors.Is(err, context.DeadlineExceeded):
+		return CategoryCancelled
+	case errors.As(err, &notFound):
+		return CategoryNotFound
+	case errors.As(err, &rateLimited):
+		return CategoryRateLimited
+	case errors.As(err, &blocked):
+		return CategoryBlocked
+	case errors.As(err, &auth):
+		return CategoryAuth
+	case errors.As(err, &schema):
+		return CategorySchemaChanged
+	case errors.As(err, &network):
+		return CategoryNetwork
+	default:
+		return CategoryOther
+	}
+}
+
+// wrapRequestError converts a transport error into a NetworkError, leaving context errors intact
+func wrapRequestError(ctx context.Context, url string, err error) error {
+	if ctx.Err() != nil {
+		return ctx.Err()
+	}
+	return &NetworkError{URL: url, Err: err}
+}
+
+// checkResponse maps a non-200 response to a typed error. It consumes the body on failure.
+func checkResponse(resp *http.Response, url string) error {
+	if resp.StatusCode == http.StatusOK {
+		return nil
+	}
+
+	body, _ := io.ReadAll(io.LimitReader(resp.Body, 64*1024))
+
+	switch {
+	case resp.StatusCode == http.StatusNotFound, resp.StatusCode == http.StatusGone:
+		return &NotFoundError{URL: url}
+	case resp.StatusCode == http.StatusTooManyRequests:
+		return &RateLimitedError{URL: url, RetryAfter: parseRetryAfter(resp.Header.Get("Retry-After"))}
+	case resp.StatusCode == http.StatusUnauthorized:
+		return &AuthError{Message: fmt.Sprintf("%s returned %s", url, resp.Status)}
+	case resp.StatusCode == http.StatusForbidden:
+		if isCaptchaPage(body) {
+			return &BlockedError{URL: url, StatusCode: resp.StatusCode, Reason: "captcha"}
+		}
+		return &BlockedError{URL: url, StatusCode: resp.StatusCode, Reason: "forbidden"}
+	case resp.StatusCode >= 500:
+		if isCaptchaPage(body) {
+			return &BlockedError{URL: url, StatusCode: resp.StatusCode, Reason: "captcha"}
+		}
+		return &NetworkError{
+			URL:        url,
+			Err:        fmt.Errorf("HTTP error: %s", resp.Status),
+			RetryAfter: parseRetryAfter(resp.Header.Get("Retry-After")),
+		}
+	default:
+		return fmt.Errorf("HTTP error for %s: %s", url, resp.Status)
+	}
+}
+
+// isCaptchaPage reports whether body looks like a bot-check page
+func isCaptchaPage(body []byte) bool {
+	lower := strings.ToLower(string(body))
+	return strings.Contains(lower, "captcha") || strings.Contains(lower, "robot check")
+}
+
+// parseRetryAfter parses a Retry-After header given in seconds or as an HTTP date
+func parseRetryAfter(value string) time.Duration {
+	if value == "" {
+		return 0
+	}
+	if seconds, err := strconv.Atoi(strings.TrimSpace(value)); err == nil {
+		return time.Duration(seconds) * time.Second
+	}
+	if t, err := http.ParseTime(value); err == nil {
+		if d := time.Until(t); d > 0 {
+			return d
+		}
+	}
+	return 0
+}
@@ -0,0 +1,89 @@
+package scraper
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestCheckResponse(t *testing.T) {
+	tests := []struct {
+		name       string
+		statusCode int
+		header     http.Header
+		body       string
+		category   string
+	}{
+		{name: "OK", statusCode: http.StatusOK, category: ""},
+		{name: "Not found", statusCode: http.StatusNotFound, category: CategoryNotFound},
+		{name: "Rate limited", statusCode: http.StatusTooManyRequests, category: CategoryRateLimited},
+		{name: "Unauthorized", statusCode: http.StatusUnauthorized, category: CategoryAuth},
+		{name: "Forbidden", statusCode: http.StatusForbidden, category: CategoryBlocked},
+		{name: "Captcha", statusCode: http.StatusServiceUnavailable, body: "<title>Robot Check</title> enter the captcha", category: CategoryBlocked},
+		{name: "Server error", statusCode: http.StatusBadGateway, category: CategoryNetwork},
+		{name: "Other status", statusCode: http.StatusTeapot, category: CategoryOther},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			resp := &http.Response{
+				StatusCode: tt.statusCode,
+				Status:     fmt.Sprintf("%d %s", tt.statusCode, http.StatusText(tt.statusCode)),
+				Header:     tt.header,
+				Body:       io.NopCloser(strings.NewReader(tt.body)),
+			}
+			if resp.Header == nil {
+				resp.Header = http.Header{}
+			}
+
+			err := checkResponse(resp, "https://www.goodreads.com/book/show/1/reviews")
+			if got := ErrorCategory(err); got != tt.category {
+				t.Errorf("ErrorCategory(checkResponse()) = %q, want %q (err: %v)", got, tt.category, err)
+			}
+		})
+	}
+}
+
+func TestRateLimitedRetryAfter(t *testing.T) {
+	resp := &http.Response{
+		StatusCode: http.StatusTooManyRequests,
+		Status:     "429 Too Many Requests",
+		Header:     http.Header{"Retry-After": []string{"7"}},
+		Body:       io.NopCloser(strings.NewReader("")),
+	}
+
+	err := checkResponse(resp, "https://example.com")
+	rateLimited, ok := err.(*RateLimitedError)
+	if !ok {
+		t.Fatalf("Expected *RateLimitedError, got %T", err)
+	}
+	if rateLimited.RetryAfter != 7*time.Second {
+		t.Errorf("Expected RetryAfter 7s, got %s", rateLimited.RetryAfter)
+	}
+}
+
+func TestErrorCategoryWrapped(t *testing.T) {
+	tests := []struct {
+		name     string
+		err      error
+		expected string
+	}{
+		{"Nil", nil, ""},
+		{"Cancelled", fmt.Errorf("stopped: %w", context.Canceled), CategoryCancelled},
+		{"Wrapped network", fmt.Errorf("stopped: %w", &NetworkError{URL: "u", Err: io.EOF}), CategoryNetwork},
+		{"Schema", &SchemaChangedError{What: "page"}, CategorySchemaChanged},
+		{"Plain", fmt.Errorf("boom"), CategoryOther},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := ErrorCategory(tt.err); got != tt.expected {
+				t.Errorf("ErrorCategory(%v) = %q, want %q", tt.err, got, tt.expected)
+			}
+		})
+	}
+}
@@ -0,0 +1,148 @@
+package scraper
+
+import (
+	"fmt"
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/rizkirmdhnnn/goodreadscrape/internal/models"
+)
+
+// graphQLSorts maps review sort orders to the GraphQL sort filter. The default
+// order is requested by leaving the filter out.
+var graphQLSorts = map[models.ReviewSort]string{
+	models.SortNewest: "NEWEST",
+	models.SortOldest: "OLDEST",
+}
+
+// ParseSort validates a review sort order name. An empty name, "default" or
+// "popular" selects Goodreads' default order.
+func ParseSort(name string) (models.ReviewSort, error) {
+	switch strings.ToLower(name) {
+	case "", "default", "popular":
+		return models.SortDefault, nil
+	case "newest":
+		return models.SortNewest, nil
+	case "oldest":
+		return models.SortOldest, nil
+	default:
+		return "", fmt.Errorf("unknown sort order %q (use default, newest or oldest)", name)
+	}
+}
+
+// ParseStars parses a star rating filter: a rating from 1 to 5, or a range
+// such as 4-5. An empty value returns 0, 0 for no filter.
+func ParseStars(value string) (int, int, error) {
+	if value == "" {
+		return 0, 0, nil
+	}
+
+	low, high, isRange := strings.Cut(value, "-")
+	if !isRange {
+		high = low
+	}
+	minRating, err1 := strconv.Atoi(strings.TrimSpace(low))
+	maxRating, err2 := strconv.Atoi(strings.TrimSpace(high))
+	if err1 != nil || err2 != nil || minRating < 1 || maxRating > 5 || minRating > maxRating {
+		return 0, 0, fmt.Errorf("invalid star rating filter %q (use a rating from 1 to 5, or a range such as 4-5)", value)
+	}
+	return minRating, maxRating, nil
+}
+
+// languageCodePattern matches review language codes such as en or pt-BR
+var languageCodePattern = regexp.MustCompile(`^[A-Za-z]{2,3}(-[A-Za-z0-9]{2,8})*$`)
+
+// ParseLanguages parses a comma-separated list of review language codes,
+// dropping duplicates. An empty value or "all" returns nil, for reviews in
+// every language.
+func ParseLanguages(value string) ([]string, error) {
+	if strings.TrimSpace(value) == "" || strings.EqualFold(strings.TrimSpace(value), "all") {
+		return nil, nil
+	}
+
+	var languages []string
+	for _, code := range strings.Split(value, ",") {
+		code = strings.TrimSpace(code)
+		if strings.EqualFold(code, "all") {
+			return nil, fmt.Errorf("language %q cannot be combined with other languages", code)
+		}
+		if !languageCodePattern.MatchString(code) {
+			return nil, fmt.Errorf("invalid language code %q (use codes such as id or en, comma-separated, or all)", code)
+		}
+		if !slices.Contains(languages, code) {
+			languages = append(languages, code)
+		}
+	}
+	return languages, nil
+}
+
+// graphQLFilters builds the BookReviewsFilterInput of a work's reviews query
+// in language, or in every language if empty
+func graphQLFilters(workID string, language string, filters models.Filters) map[string]interface{} {
+	input := map[string]interface{}{
+		"resourceType": "WORK",
+		"resourceId":   workID,
+	}
+
+	if language != "" {
+		input["languageCode"] = language
+	}
+
+	// Incremental fetches need the newest reviews first to stop at known ones
+	sort := filters.Sort
+	if filters.Known != nil {
+		sort = models.SortNewest
+	}
+	if value, ok := graphQLSorts[sort]; ok {
+		input["sort"] = value
+	}
+
+	if filters.MinRating > 0 {
+		input["ratingMin"] = filters.MinRating
+	}
+	if filters.MaxRating > 0 {
+		input["ratingMax"] = filters.MaxRating
+	}
+	if filters.TextOnly {
+		input["textOnly"] = true
+	}
+	if filters.Search != "" {
+		input["searchText"] = filters.Search
+	}
+	return input
+}
+
+// A language cursor continues fetching reviews in several languages. It names
+// the language being fetched, how many of its reviews were fetched so far and
+// the page token within it: "<language>|<count>|<token>". Languages before it
+// in the list were fetched completely.
+
+// formatCursor returns the cursor continuing language after count reviews
+func formatCursor(language string, count int, token string) string {
+	return fmt.Sprintf("%s|%d|%s", language, count, token)
+}
+
+// parseCursor returns the index in languages of the language a cursor
+// continues, the reviews of it fetched so far and the page token. An empty
+// cursor starts with the first language.
+func parseCursor(cursor string, languages []string) (int, int, string, error) {
+	if cursor == "" {
+		return 0, 0, "", nil
+	}
+
+	parts := strings.SplitN(cursor, "|", 3)
+	if len(parts) != 3 {
+		return 0, 0, "", fmt.Errorf("invalid page token %q: not a language cursor", cursor)
+	}
+	index := slices.Index(languages, parts[0])
+	if index < 0 {
+		return 0, 0, "", fmt.Errorf("invalid page token %q: language %s is not being fetched", cursor, parts[0])
+	}
+	count, err := strconv.Atoi(parts[1])
+	if err != nil || count < 0 {
+		return 0, 0, "", fmt.Errorf("invalid page token %q: bad review count", cursor)
+	}
+	return index, count, parts[2], nil
+}
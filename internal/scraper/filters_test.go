@@ -0,0 +1,154 @@
+package scraper
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/rizkirmdhnnn/goodreadscrape/internal/models"
+)
+
+func TestParseSort(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected models.ReviewSort
+		wantErr  bool
+	}{
+		{"", models.SortDefault, false},
+		{"popular", models.SortDefault, false},
+		{"Newest", models.SortNewest, false},
+		{"oldest", models.SortOldest, false},
+		{"random", "", true},
+	}
+
+	for _, tt := range tests {
+		got, err := ParseSort(tt.input)
+		if (err != nil) != tt.wantErr {
+			t.Errorf("ParseSort(%q) error = %v, wantErr %v", tt.input, err, tt.wantErr)
+		}
+		if got != tt.expected {
+			t.Errorf("ParseSort(%q) = %q, want %q", tt.input, got, tt.expected)
+		}
+	}
+}
+
+func TestParseStars(t *testing.T) {
+	tests := []struct {
+		input   string
+		min     int
+		max     int
+		wantErr bool
+	}{
+		{"", 0, 0, false},
+		{"5", 5, 5, false},
+		{"4-5", 4, 5, false},
+		{"1 - 2", 1, 2, false},
+		{"0", 0, 0, true},
+		{"5-4", 0, 0, true},
+		{"3-6", 0, 0, true},
+		{"four", 0, 0, true},
+	}
+
+	for _, tt := range tests {
+		minRating, maxRating, err := ParseStars(tt.input)
+		if (err != nil) != tt.wantErr {
+			t.Errorf("ParseStars(%q) error = %v, wantErr %v", tt.input, err, tt.wantErr)
+		}
+		if minRating != tt.min || maxRating != tt.max {
+			t.Errorf("ParseStars(%q) = %d, %d, want %d, %d", tt.input, minRating, maxRating, tt.min, tt.max)
+		}
+	}
+}
+
+func TestParseLanguages(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected []string
+		wantErr  bool
+	}{
+		{"", nil, false},
+		{"all", nil, false},
+		{"ALL", nil, false},
+		{"id", []string{"id"}, false},
+		{"id, en,id", []string{"id", "en"}, false},
+		{"pt-BR", []string{"pt-BR"}, false},
+		{"id,all", nil, true},
+		{"id,", nil, true},
+		{"e|n", nil, true},
+	}
+
+	for _, tt := range tests {
+		got, err := ParseLanguages(tt.input)
+		if (err != nil) != tt.wantErr {
+			t.Errorf("ParseLanguages(%q) error = %v, wantErr %v", tt.input, err, tt.wantErr)
+		}
+		if !reflect.DeepEqual(got, tt.expected) {
+			t.Errorf("ParseLanguages(%q) = %q, want %q", tt.input, got, tt.expected)
+		}
+	}
+}
+
+func TestParseCursor(t *testing.T) {
+	languages := []string{"id", "en"}
+	index, count, token, err := parseCursor(formatCursor("en", 12, "tok|en"), languages)
+	if err != nil {
+		t.Fatalf("parseCursor failed: %v", err)
+	}
+	if index != 1 || count != 12 || token != "tok|en" {
+		t.Errorf("Expected en after 12 reviews at tok|en, got %d, %d, %q", index, count, token)
+	}
+
+	for _, cursor := range []string{"plain-token", "fr|0|", "en|x|tok"} {
+		if _, _, _, err := parseCursor(cursor, languages); err == nil {
+			t.Errorf("Expected an error for cursor %q", cursor)
+		}
+	}
+}
+
+func TestGraphQLFilters(t *testing.T) {
+	tests := []struct {
+		name     string
+		language string
+		filters  models.Filters
+		expected map[string]interface{}
+	}{
+		{
+			name:     "No filters",
+			filters:  models.Filters{},
+			expected: map[string]interface{}{"resourceType": "WORK", "resourceId": "w1"},
+		},
+		{
+			name:     "Every filter",
+			language: "en",
+			filters: models.Filters{
+				Sort:      models.SortOldest,
+				MinRating: 4,
+				MaxRating: 5,
+				TextOnly:  true,
+				Search:    "plot twist",
+			},
+			expected: map[string]interface{}{
+				"resourceType": "WORK",
+				"resourceId":   "w1",
+				"languageCode": "en",
+				"sort":         "OLDEST",
+				"ratingMin":    4,
+				"ratingMax":    5,
+				"textOnly":     true,
+				"searchText":   "plot twist",
+			},
+		},
+		{
+			name:     "Incremental sorts newest first",
+			filters:  models.Filters{Sort: models.SortOldest, Known: models.KnownReviews{}},
+			expected: map[string]interface{}{"resourceType": "WORK", "resourceId": "w1", "sort": "NEWEST"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := graphQLFilters("w1", tt.language, tt.filters); !reflect.DeepEqual(got, tt.expected) {
+				t.Errorf("graphQLFilters() = %v, want %v", got, tt.expected)
+			}
+		})
+	}
+}
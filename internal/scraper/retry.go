@@ -0,0 +1,113 @@
+package scraper
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math/rand"
+	"time"
+)
+
+// RetryPolicy controls how transient failures (429, 5xx, connection errors) are retried
+type RetryPolicy struct {
+	MaxAttempts int           // Total attempts including the first; values below 1 disable retries
+	BaseDelay   time.Duration // Delay before the first retry, doubled on every subsequent retry
+	MaxDelay    time.Duration // Upper bound for a single delay, including Retry-After
+	Jitter      float64       // Fraction of the delay (0-1) randomised to spread out retries
+}
+
+// DefaultRetryPolicy returns the policy used when none is configured
+func DefaultRetryPolicy() RetryPolicy {
+	return RetryPolicy{
+		MaxAttempts: 4,
+		BaseDelay:   1 * time.Second,
+		MaxDelay:    30 * time.Second,
+		Jitter:      0.2,
+	}
+}
+
+// WithRetryPolicy sets the retry policy used for page and GraphQL requests
+func WithRetryPolicy(policy RetryPolicy) Option {
+	return func(s *goodreadsScraper) {
+		s.retryPolicy = policy
+	}
+}
+
+// delay returns how long to wait before retry number attempt (starting at 1).
+// A server-provided Retry-After takes precedence over the computed backoff.
+func (p RetryPolicy) delay(attempt int, retryAfter time.Duration) time.Duration {
+	if retryAfter > 0 {
+		if p.MaxDelay > 0 && retryAfter > p.MaxDelay {
+			return p.MaxDelay
+		}
+		return retryAfter
+	}
+
+	d := p.BaseDelay
+	for i := 1; i < attempt && (p.MaxDelay <= 0 || d < p.MaxDelay); i++ {
+		d *= 2
+	}
+	if p.MaxDelay > 0 && d > p.MaxDelay {
+		d = p.MaxDelay
+	}
+	if p.Jitter > 0 && d > 0 {
+		spread := float64(d) * p.Jitter
+		d += time.Duration(spread * (2*rand.Float64() - 1))
+	}
+	return d
+}
+
+// isRetryable reports whether err is a transient failure worth retrying
+func isRetryable(err error) bool {
+	var (
+		rateLimited *RateLimitedError
+		network     *NetworkError
+	)
+	return errors.As(err, &rateLimited) || errors.As(err, &network)
+}
+
+// retryAfterOf extracts a server-provided Retry-After delay from err, if any
+func retryAfterOf(err error) time.Duration {
+	var (
+		rateLimited *RateLimitedError
+		network     *NetworkError
+	)
+	if errors.As(err, &rateLimited) {
+		return rateLimited.RetryAfter
+	}
+	if errors.As(err, &network) {
+		return network.RetryAfter
+	}
+	return 0
+}
+
+// withRetry runs fn until it succeeds, fails permanently, ctx is cancelled or
+// the policy's attempts are exhausted. desc identifies the request in logs.
+func (s *goodreadsScraper) withRetry(ctx context.Context, desc string, fn func() error) error {
+	attempts := max(s.retryPolicy.MaxAttempts, 1)
+
+	var err error
+	for attempt := 1; ; attempt++ {
+		err = fn()
+		if err == nil || ctx.Err() != nil || !isRetryable(err) || attempt >= attempts {
+			return err
+		}
+
+		wait := s.retryPolicy.delay(attempt, retryAfterOf(err))
+		s.retries.Add(1)
+		if s.verbose {
+			fmt.Printf("🔁 Retrying %s in %s (attempt %d/%d): %v\n", desc, wait.Round(time.Millisecond), attempt+1, attempts, err)
+		}
+
+		select {
+		case <-time.After(wait):
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+	}
+}
+
+// RetryCount returns the number of retries performed since the scraper was created
+func (s *goodreadsScraper) RetryCount() int64 {
+	return s.retries.Load()
+}
@@ -0,0 +1,89 @@
+package scraper
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestRetryPolicyDelay(t *testing.T) {
+	policy := RetryPolicy{MaxAttempts: 5, BaseDelay: 100 * time.Millisecond, MaxDelay: 300 * time.Millisecond}
+
+	tests := []struct {
+		attempt    int
+		retryAfter time.Duration
+		expected   time.Duration
+	}{
+		{1, 0, 100 * time.Millisecond},
+		{2, 0, 200 * time.Millisecond},
+		{3, 0, 300 * time.Millisecond},
+		{10, 0, 300 * time.Millisecond},
+		{1, 250 * time.Millisecond, 250 * time.Millisecond},
+		{1, 5 * time.Second, 300 * time.Millisecond},
+	}
+
+	for _, tt := range tests {
+		if got := policy.delay(tt.attempt, tt.retryAfter); got != tt.expected {
+			t.Errorf("delay(%d, %s) = %s; expected %s", tt.attempt, tt.retryAfter, got, tt.expected)
+		}
+	}
+}
+
+func TestFetchPageRetries(t *testing.T) {
+	tests := []struct {
+		name         string
+		failures     int
+		failStatus   int
+		maxAttempts  int
+		wantErr      bool
+		wantRequests int32
+		wantRetries  int64
+		wantCategory string
+	}{
+		{name: "Recovers from 503", failures: 2, failStatus: http.StatusServiceUnavailable, maxAttempts: 4, wantRequests: 3, wantRetries: 2},
+		{name: "Recovers from 429", failures: 1, failStatus: http.StatusTooManyRequests, maxAttempts: 4, wantRequests: 2, wantRetries: 1},
+		{name: "Gives up after max attempts", failures: 10, failStatus: http.StatusBadGateway, maxAttempts: 3, wantErr: true, wantRequests: 3, wantRetries: 2, wantCategory: CategoryNetwork},
+		{name: "Does not retry 404", failures: 10, failStatus: http.StatusNotFound, maxAttempts: 4, wantErr: true, wantRequests: 1, wantRetries: 0, wantCategory: CategoryNotFound},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var requests atomic.Int32
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				if int(requests.Add(1)) <= tt.failures {
+					w.Header().Set("Retry-After", "0")
+					w.WriteHeader(tt.failStatus)
+					return
+				}
+				w.Write([]byte("<html>ok</html>"))
+			}))
+			defer server.Close()
+
+			s := NewGoodreadsScraper("test", false, WithRetryPolicy(RetryPolicy{
+				MaxAttempts: tt.maxAttempts,
+				BaseDelay:   time.Millisecond,
+				MaxDelay:    5 * time.Millisecond,
+			})).(*goodreadsScraper)
+
+			body, err := s.fetchPage(context.Background(), server.URL)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("fetchPage() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if !tt.wantErr && string(body) != "<html>ok</html>" {
+				t.Errorf("Unexpected body: %s", body)
+			}
+			if got := ErrorCategory(err); got != tt.wantCategory {
+				t.Errorf("ErrorCategory() = %q, want %q", got, tt.wantCategory)
+			}
+			if got := requests.Load(); got != tt.wantRequests {
+				t.Errorf("Expected %d requests, got %d", tt.wantRequests, got)
+			}
+			if got := s.RetryCount(); got != tt.wantRetries {
+				t.Errorf("Expected %d retries, got %d", tt.wantRetries, got)
+			}
+		})
+	}
+}
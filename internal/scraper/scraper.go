@@ -2,62 +2,167 @@ package scraper
 
 import (
 	"bytes"
+	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"io"
-	"log"
 	"net/http"
+	"net/url"
 	"os"
 	"regexp"
 	"strconv"
 	"strings"
+	"sync/atomic"
 	"time"
 
 	"github.com/PuerkitoBio/goquery"
+	"github.com/rizkirmdhnnn/goodreadscrape/internal/langdetect"
 	"github.com/rizkirmdhnnn/goodreadscrape/internal/models"
+	"github.com/rizkirmdhnnn/goodreadscrape/internal/parser"
 )
 
+// Default endpoints used when no override is configured
+const (
+	DefaultBaseURL    = "https://www.goodreads.com"
+	DefaultGraphQLURL = "https://kxbwmqov6jgg3daaamb744ycu4.appsync-api.us-east-1.amazonaws.com/graphql"
+)
+
+// userAgent mimics a real browser for Goodreads page requests
+const userAgent = "Mozilla/5.0 (Macintosh; Intel Mac OS X 10_15_7) AppleWebKit/537.36 (KHTML, like Gecko) Chrome/120.0.0.0 Safari/537.36"
+
 // Define GoodreadsScraper interface and its implementation
 type goodreadsScraper struct {
 	apiKey  string
 	verbose bool
+	client  HTTPClient
+
+	baseURL    string
+	graphqlURL string
+
+	retryPolicy RetryPolicy
+	retries     atomic.Int64
+
+	pageLimiter    RateLimiter
+	graphqlLimiter RateLimiter
+}
+
+// RateLimiter throttles outgoing requests. Implementations must be safe for concurrent use.
+type RateLimiter interface {
+	Wait(ctx context.Context) error
 }
 
 type GoodreadsScraper interface {
-	ScrapeBookData(bookURL string, maxReviews int, filters models.Filters) (models.BookData, error)
-	ExtractBookMetadata(bookURL string) (models.BookMetadata, error)
-	ExtractWorkID(bookURL string) (string, error)
-	FetchReviewsGraphQL(workID string, maxReviews int, languageCode string, bookMetadata models.BookMetadata) ([]models.Review, error)
+	ScrapeBookData(ctx context.Context, bookURL string, maxReviews int, filters models.Filters) (models.BookData, error)
+	ScrapeBookDataFromHTML(ctx context.Context, bookURL string, html []byte, maxReviews int, filters models.Filters) (models.BookData, error)
+	FetchBookPage(ctx context.Context, bookURL string) ([]byte, error)
+	ExtractBookMetadata(ctx context.Context, bookURL string) (models.BookMetadata, error)
+	ExtractWorkID(ctx context.Context, bookURL string) (string, error)
+	FetchReviewsGraphQL(ctx context.Context, workID string, maxReviews int, languageCode string, bookMetadata models.BookMetadata) ([]models.Review, error)
+	RetryCount() int64
+}
+
+// Option configures a GoodreadsScraper
+type Option func(*goodreadsScraper)
+
+// WithHTTPClient sets the client used for every page and GraphQL request
+func WithHTTPClient(client HTTPClient) Option {
+	return func(s *goodreadsScraper) {
+		if client != nil {
+			s.client = client
+		}
+	}
 }
 
-func NewGoodreadsScraper(apiKey string, verbose bool) GoodreadsScraper {
-	return &goodreadsScraper{
-		apiKey:  apiKey,
-		verbose: verbose,
+// WithBaseURL sets the scheme and host (plus optional path prefix) that book
+// pages are fetched from, e.g. a local fake server or a caching mirror
+func WithBaseURL(baseURL string) Option {
+	return func(s *goodreadsScraper) {
+		if baseURL != "" {
+			s.baseURL = strings.TrimSuffix(baseURL, "/")
+		}
 	}
 }
 
+// WithGraphQLURL sets the GraphQL endpoint used to fetch reviews
+func WithGraphQLURL(graphqlURL string) Option {
+	return func(s *goodreadsScraper) {
+		if graphqlURL != "" {
+			s.graphqlURL = graphqlURL
+		}
+	}
+}
+
+// WithPageLimiter sets the limiter consulted before every HTML page request
+func WithPageLimiter(limiter RateLimiter) Option {
+	return func(s *goodreadsScraper) {
+		s.pageLimiter = limiter
+	}
+}
+
+// WithGraphQLLimiter sets the limiter consulted before every GraphQL request
+func WithGraphQLLimiter(limiter RateLimiter) Option {
+	return func(s *goodreadsScraper) {
+		s.graphqlLimiter = limiter
+	}
+}
+
+func NewGoodreadsScraper(apiKey string, verbose bool, opts ...Option) GoodreadsScraper {
+	s := &goodreadsScraper{
+		apiKey:      apiKey,
+		verbose:     verbose,
+		client:      NewHTTPClient(),
+		baseURL:     DefaultBaseURL,
+		graphqlURL:  DefaultGraphQLURL,
+		retryPolicy: DefaultRetryPolicy(),
+	}
+	for _, opt := range opts {
+		opt(s)
+	}
+	return s
+}
+
 // Implement the methods of GoodreadsScraper interface here
-func (s *goodreadsScraper) ScrapeBookData(bookURL string, maxReviews int, filters models.Filters) (models.BookData, error) {
-	// Extract Metadata
-	metadata, err := s.ExtractBookMetadata(bookURL)
+func (s *goodreadsScraper) ScrapeBookData(ctx context.Context, bookURL string, maxReviews int, filters models.Filters) (models.BookData, error) {
+	// Fetch the book page once; it feeds both metadata and work ID extraction
+	html, err := s.FetchBookPage(ctx, bookURL)
 	if err != nil {
 		return models.BookData{}, err
 	}
 
-	// Extract Work ID
-	workID, err := s.ExtractWorkID(bookURL)
+	return s.ScrapeBookDataFromHTML(ctx, bookURL, html, maxReviews, filters)
+}
+
+// ScrapeBookDataFromHTML scrapes a book from an already-fetched (or saved) book
+// page: metadata and work ID come from html, reviews from the GraphQL API
+func (s *goodreadsScraper) ScrapeBookDataFromHTML(ctx context.Context, bookURL string, html []byte, maxReviews int, filters models.Filters) (models.BookData, error) {
+	// Extract Metadata
+	metadata, err := ParseBookMetadata(html, bookURL)
 	if err != nil {
 		return models.BookData{}, err
 	}
 
+	scrapedAt := time.Now().UTC()
+
+	// Work ID comes from the same page state as the metadata
+	workID := metadata.WorkID
+	if workID == "" {
+		return models.BookData{Metadata: metadata, ScrapedAt: scrapedAt}, workIDNotFound(html)
+	}
+	if s.verbose {
+		fmt.Printf("✅ Found work ID: %s\n", workID)
+	}
+
 	// Fetch Reviews using GraphQL API
-	reviews, err := s.FetchReviewsGraphQL(workID, maxReviews, filters.Language, metadata)
+	reviews, nextPageToken, err := s.fetchReviews(ctx, workID, maxReviews, filters, metadata)
 	if err != nil {
-		fmt.Printf("⚠️ Warning: Failed to fetch reviews via GraphQL: %v\n", err)
+		// Return whatever was fetched before the failure so the caller can still save it
 		return models.BookData{
-			Metadata: metadata,
-		}, nil
+			Metadata:      metadata,
+			Reviews:       reviews,
+			ScrapedAt:     scrapedAt,
+			NextPageToken: nextPageToken,
+		}, err
 	}
 
 	if s.verbose {
@@ -65,130 +170,155 @@ func (s *goodreadsScraper) ScrapeBookData(bookURL string, maxReviews int, filter
 	}
 
 	return models.BookData{
-		Metadata: metadata,
-		Reviews:  reviews,
+		Metadata:      metadata,
+		Reviews:       reviews,
+		ScrapedAt:     scrapedAt,
+		NextPageToken: nextPageToken,
 	}, nil
 }
 
-// Implement the methods of GoodreadsScraper interface here
-func (s *goodreadsScraper) ExtractBookMetadata(bookURL string) (models.BookMetadata, error) {
-	// Convert bookURL to review URL
-	ReviewURL := bookURL + "/reviews"
-
-	// Request to url
-	res, err := http.Get(ReviewURL)
+// FetchBookPage downloads the reviews page of a book
+func (s *goodreadsScraper) FetchBookPage(ctx context.Context, bookURL string) ([]byte, error) {
+	reviewsURL, err := s.reviewsURL(bookURL)
 	if err != nil {
-		log.Fatal(err)
+		return nil, err
 	}
-	defer res.Body.Close()
+	return s.fetchPage(ctx, reviewsURL)
+}
 
-	if res.StatusCode != 200 {
-		log.Fatalf("status code error: %d %s", res.StatusCode, res.Status)
+// ExtractBookMetadata fetches a book page and extracts its metadata
+func (s *goodreadsScraper) ExtractBookMetadata(ctx context.Context, bookURL string) (models.BookMetadata, error) {
+	html, err := s.FetchBookPage(ctx, bookURL)
+	if err != nil {
+		return models.BookMetadata{}, err
 	}
+	return ParseBookMetadata(html, bookURL)
+}
 
-	// Parse response
-	doc, err := goquery.NewDocumentFromReader(res.Body)
+// ExtractWorkID fetches a book page and extracts its work ID
+func (s *goodreadsScraper) ExtractWorkID(ctx context.Context, bookURL string) (string, error) {
+	html, err := s.FetchBookPage(ctx, bookURL)
 	if err != nil {
-		log.Fatal(err)
-	}
-
-	// Extract book title from the link
-	title := "Unknown Title"
-	titleElement := doc.Find("a[data-testid='title']").First()
-	if titleElement.Length() > 0 {
-		title = strings.TrimSpace(titleElement.Text())
-	}
-
-	// Extract author name from the link
-	author := "Unknown Author"
-	authorElement := doc.Find("span.ContributorLink__name[data-testid='name']").First()
-	if authorElement.Length() > 0 {
-		author = strings.TrimSpace(authorElement.Text())
-	}
-
-	// Extract average rating from the link
-	avgRating := 0.0
-	ratingElement := doc.Find("div.RatingStatistics__column").First()
-	if ratingElement.Length() > 0 {
-		// Try to get rating from aria-label first
-		ariaLabel, exists := ratingElement.Attr("aria-label")
-		if exists {
-			re := regexp.MustCompile(`(\d+\.?\d*)`)
-			matches := re.FindStringSubmatch(ariaLabel)
-			if len(matches) > 1 {
-				if rating, err := strconv.ParseFloat(matches[1], 64); err == nil {
-					avgRating = rating
-				}
-			}
-		}
-
-		// Fallback to text content if aria-label didn't work
-		if avgRating == 0.0 {
-			ratingText := strings.TrimSpace(ratingElement.Text())
-			re := regexp.MustCompile(`(\d+\.?\d*)`)
-			matches := re.FindStringSubmatch(ratingText)
-			if len(matches) > 1 {
-				if rating, err := strconv.ParseFloat(matches[1], 64); err == nil {
-					avgRating = rating
-				}
-			}
-		}
+		return "", err
 	}
 
-	return models.BookMetadata{
-		Title:         title,
-		Author:        author,
-		AverageRating: avgRating,
-		URL:           bookURL,
-	}, nil
+	workID, err := ParseWorkID(html)
+	if err == nil && s.verbose {
+		fmt.Printf("✅ Found work ID: %s\n", workID)
+	}
+	return workID, err
 }
 
-func (s *goodreadsScraper) ExtractWorkID(bookURL string) (string, error) {
-	// Convert to reviews URL
-	reviewsURL := strings.TrimSuffix(bookURL, "/") + "/reviews"
+// ParseBookMetadata extracts book metadata from the HTML of a book page. It reads
+// the embedded __NEXT_DATA__ Apollo state and falls back to CSS selectors if
+// the page has none. A state that no longer decodes is a SchemaChangedError.
+func ParseBookMetadata(html []byte, bookURL string) (models.BookMetadata, error) {
+	doc, err := goquery.NewDocumentFromReader(bytes.NewReader(html))
+	if err != nil {
+		return models.BookMetadata{}, &SchemaChangedError{What: "book page HTML", Err: err}
+	}
 
-	// Create HTTP request with headers
-	client := &http.Client{}
-	req, err := http.NewRequest("GET", reviewsURL, nil)
+	state, err := parser.ParseBookState(doc)
+	if err != nil && !errors.Is(err, parser.ErrNoNextData) {
+		return models.BookMetadata{}, &SchemaChangedError{What: "book page state", Err: err}
+	}
 	if err != nil {
-		return "", fmt.Errorf("failed to create request: %v", err)
+		// Fall back to the rendered markup
+		title, author, avgRating := parser.ExtractBookMetadata(doc, bookURL)
+		return models.BookMetadata{
+			WorkID:        parser.ExtractWorkID(html),
+			BookID:        parser.BookIDFromURL(bookURL),
+			Title:         title,
+			Author:        author,
+			AverageRating: avgRating,
+			URL:           bookURL,
+		}, nil
 	}
 
-	// Set User-Agent header to mimic a real browser
-	req.Header.Set("User-Agent", "Mozilla/5.0 (Macintosh; Intel Mac OS X 10_15_7) AppleWebKit/537.36 (KHTML, like Gecko) Chrome/120.0.0.0 Safari/537.36")
+	return state.Metadata(bookURL), nil
+}
 
-	// Make the request
-	resp, err := client.Do(req)
+// ParseWorkID extracts the work ID from the JavaScript state embedded in a book page
+func ParseWorkID(html []byte) (string, error) {
+	metadata, err := ParseBookMetadata(html, "")
 	if err != nil {
-		return "", fmt.Errorf("failed to make request: %v", err)
+		return "", err
 	}
-	defer resp.Body.Close()
-
-	// Check status code
-	if resp.StatusCode != http.StatusOK {
-		return "", fmt.Errorf("HTTP error: %d %s", resp.StatusCode, resp.Status)
+	if metadata.WorkID == "" {
+		return "", workIDNotFound(html)
 	}
+	return metadata.WorkID, nil
+}
 
-	// Read response body
-	body, err := io.ReadAll(resp.Body)
-	if err != nil {
-		return "", fmt.Errorf("failed to read response body: %v", err)
+// workIDNotFound explains why a page yielded no work ID
+func workIDNotFound(html []byte) error {
+	if isCaptchaPage(html) {
+		return &BlockedError{StatusCode: http.StatusOK, Reason: "captcha"}
 	}
+	return &SchemaChangedError{What: "book page", Err: fmt.Errorf("work ID not found in page content")}
+}
 
-	// Look for work ID in JavaScript using regex
-	workIDRegex := regexp.MustCompile(`"work":\s*{\s*"__ref":\s*"Work:(kca://work/[^"]+)"`)
-	matches := workIDRegex.FindStringSubmatch(string(body))
+// fetchPage downloads a Goodreads HTML page, retrying transient failures
+func (s *goodreadsScraper) fetchPage(ctx context.Context, pageURL string) ([]byte, error) {
+	var body []byte
+	err := s.withRetry(ctx, pageURL, func() error {
+		if err := wait(ctx, s.pageLimiter); err != nil {
+			return err
+		}
 
-	if len(matches) > 1 {
-		workID := matches[1]
-		if s.verbose {
-			fmt.Printf("✅ Found work ID: %s\n", workID)
+		req, err := http.NewRequestWithContext(ctx, "GET", pageURL, nil)
+		if err != nil {
+			return fmt.Errorf("failed to create request: %w", err)
 		}
-		return workID, nil
+
+		// Set User-Agent header to mimic a real browser
+		req.Header.Set("User-Agent", userAgent)
+
+		resp, err := s.client.Do(req)
+		if err != nil {
+			return wrapRequestError(ctx, pageURL, err)
+		}
+		defer resp.Body.Close()
+
+		if err := checkResponse(resp, pageURL); err != nil {
+			return err
+		}
+
+		body, err = io.ReadAll(resp.Body)
+		if err != nil {
+			return wrapRequestError(ctx, pageURL, err)
+		}
+		return nil
+	})
+	return body, err
+}
+
+// wait blocks on limiter if one is configured
+func wait(ctx context.Context, limiter RateLimiter) error {
+	if limiter == nil {
+		return nil
+	}
+	return limiter.Wait(ctx)
+}
+
+// reviewsURL maps a book URL onto the configured base URL and appends /reviews
+func (s *goodreadsScraper) reviewsURL(bookURL string) (string, error) {
+	parsed, err := url.Parse(bookURL)
+	if err != nil {
+		return "", fmt.Errorf("invalid book URL %q: %w", bookURL, err)
 	}
 
-	fmt.Println("❌ Work ID not found in JavaScript")
-	return "", fmt.Errorf("work ID not found in page content")
+	// Keep only the book path so URLs already pointing at a prefixed mirror aren't doubled
+	path := strings.TrimSuffix(parsed.EscapedPath(), "/")
+	if idx := strings.Index(path, "/book/show/"); idx > 0 {
+		path = path[idx:]
+	}
+
+	base := s.baseURL
+	if base == "" {
+		base = DefaultBaseURL
+	}
+	return base + path + "/reviews", nil
 }
 
 // GraphQL types for API responses
@@ -258,25 +388,102 @@ type ReviewNode struct {
 			WebURL      string      `json:"webUrl"`
 			Typename    string      `json:"__typename"`
 		} `json:"shelf"`
-		Taggings []interface{} `json:"taggings"`
-		WebURL   string        `json:"webUrl"`
-		Typename string        `json:"__typename"`
+		Taggings []Tagging `json:"taggings"`
+		WebURL   string    `json:"webUrl"`
+		Typename string    `json:"__typename"`
 	} `json:"shelving"`
 }
 
+// Tagging is a user tag (custom shelf) attached to a review's shelving
+type Tagging struct {
+	Tag struct {
+		Name     string `json:"name"`
+		WebURL   string `json:"webUrl"`
+		Typename string `json:"__typename"`
+	} `json:"tag"`
+	Typename string `json:"__typename"`
+}
+
 type GraphQLRequest struct {
 	OperationName string                 `json:"operationName"`
 	Variables     map[string]interface{} `json:"variables"`
 	Query         string                 `json:"query"`
 }
 
-// FetchReviewsGraphQL fetches reviews using GraphQL API
-func (s *goodreadsScraper) FetchReviewsGraphQL(workID string, maxReviews int, languageCode string, bookMetadata models.BookMetadata) ([]models.Review, error) {
+// FetchReviewsGraphQL fetches reviews using GraphQL API. languageCode is a
+// comma-separated list of language codes, fetched one after the other, or
+// "all" (or empty) for every language. If ctx is cancelled mid-pagination, the
+// reviews fetched so far are returned along with ctx's error.
+func (s *goodreadsScraper) FetchReviewsGraphQL(ctx context.Context, workID string, maxReviews int, languageCode string, bookMetadata models.BookMetadata) ([]models.Review, error) {
+	languages, err := ParseLanguages(languageCode)
+	if err != nil {
+		return nil, err
+	}
+	reviews, _, err := s.fetchReviews(ctx, workID, maxReviews, models.Filters{Languages: languages}, bookMetadata)
+	return reviews, err
+}
+
+// fetchReviews fetches reviews starting at the page after filters.After (from
+// the first page if empty). It also returns the token of the page following
+// the last one processed, which is empty once every review was fetched; on
+// error that is the page that failed, so fetching can continue from there.
+// With filters.Known, fetching stops at the first review seen by an earlier
+// run, as if every review was fetched.
+//
+// Several languages are fetched one after the other, and the token returned
+// is then a language cursor (see formatCursor). maxReviews applies to each
+// language with filters.MaxPerLanguage, else to all of them together.
+func (s *goodreadsScraper) fetchReviews(ctx context.Context, workID string, maxReviews int, filters models.Filters, bookMetadata models.BookMetadata) ([]models.Review, string, error) {
+	if len(filters.Languages) <= 1 {
+		language := ""
+		if len(filters.Languages) == 1 {
+			language = filters.Languages[0]
+		}
+		return s.fetchLanguage(ctx, workID, maxReviews, language, filters.After, filters, bookMetadata)
+	}
+
+	start, fetched, afterToken, err := parseCursor(filters.After, filters.Languages)
+	if err != nil {
+		return nil, filters.After, err
+	}
+
+	var reviews []models.Review
+	for i := start; i < len(filters.Languages); i++ {
+		language := filters.Languages[i]
+		limit := maxReviews - len(reviews)
+		if filters.MaxPerLanguage {
+			limit = maxReviews - fetched
+		}
+
+		batch, next, err := s.fetchLanguage(ctx, workID, limit, language, afterToken, filters, bookMetadata)
+		reviews = append(reviews, batch...)
+		fetched += len(batch)
+		if err != nil {
+			return reviews, formatCursor(language, fetched, next), err
+		}
+
+		// The overall limit was reached: continue from here next time
+		if !filters.MaxPerLanguage && len(reviews) >= maxReviews {
+			if next != "" {
+				return reviews, formatCursor(language, fetched, next), nil
+			}
+			if i+1 < len(filters.Languages) {
+				return reviews, formatCursor(filters.Languages[i+1], 0, ""), nil
+			}
+		}
+		fetched, afterToken = 0, ""
+	}
+	return reviews, "", nil
+}
+
+// fetchLanguage fetches reviews in language (every language if empty) as
+// fetchReviews does, from the page after afterToken. Reviews record language
+// as the language they were fetched under.
+func (s *goodreadsScraper) fetchLanguage(ctx context.Context, workID string, maxReviews int, language string, afterToken string, filters models.Filters, bookMetadata models.BookMetadata) ([]models.Review, string, error) {
 	var reviews []models.Review
-	var afterToken string
 	limit := 100 // API limit per request
 
-	graphqlURL := "https://kxbwmqov6jgg3daaamb744ycu4.appsync-api.us-east-1.amazonaws.com/graphql"
+	graphqlURL := s.graphqlURL
 
 	// Get API key from environment variable
 	apiKey := os.Getenv("GOODREADS_API_KEY")
@@ -284,7 +491,7 @@ func (s *goodreadsScraper) FetchReviewsGraphQL(workID string, maxReviews int, la
 		apiKey = s.apiKey
 	}
 	if apiKey == "" || apiKey == "xxxxxx" {
-		return nil, fmt.Errorf("API key not set! Please set the GOODREADS_API_KEY environment variable or provide it via constructor")
+		return nil, afterToken, &AuthError{Message: "API key not set! Please set the GOODREADS_API_KEY environment variable or provide it via constructor"}
 	}
 
 	headers := map[string]string{
@@ -400,31 +607,18 @@ func (s *goodreadsScraper) FetchReviewsGraphQL(workID string, maxReviews int, la
         }
         `
 
-	client := &http.Client{
-		Timeout: 30 * time.Second,
-	}
-
 	if s.verbose {
 		fmt.Printf("🚀 Starting GraphQL review fetch for work ID: %s\n", workID)
-		fmt.Printf("📋 Target: %d reviews, Language: %s\n", maxReviews, languageCode)
+		fmt.Printf("📋 Target: %d reviews, Language: %s\n", maxReviews, language)
 	}
 
 	for len(reviews) < maxReviews {
-		filters := map[string]interface{}{
-			"resourceType": "WORK",
-			"resourceId":   workID,
-		}
-
-		// Add language filter if specified
-		if languageCode != "" {
-			filters["languageCode"] = languageCode
-			if s.verbose {
-				fmt.Printf("🌍 Filtering reviews by language: %s\n", languageCode)
-			}
+		if language != "" && s.verbose {
+			fmt.Printf("🌍 Filtering reviews by language: %s\n", language)
 		}
 
 		variables := map[string]interface{}{
-			"filters": filters,
+			"filters": graphQLFilters(workID, language, filters),
 			"pagination": map[string]interface{}{
 				"limit": min(limit, maxReviews-len(reviews)),
 			},
@@ -442,75 +636,23 @@ func (s *goodreadsScraper) FetchReviewsGraphQL(workID string, maxReviews int, la
 
 		jsonPayload, err := json.Marshal(payload)
 		if err != nil {
-			return reviews, fmt.Errorf("error marshaling GraphQL payload: %v", err)
-		}
-
-		req, err := http.NewRequest("POST", graphqlURL, bytes.NewBuffer(jsonPayload))
-		if err != nil {
-			return reviews, fmt.Errorf("error creating request: %v", err)
-		}
-
-		// Set headers
-		for key, value := range headers {
-			req.Header.Set(key, value)
-		}
-
-		resp, err := client.Do(req)
-		if err != nil {
-			fmt.Printf("❌ Error fetching reviews from GraphQL: %v\n", err)
-			break
-		}
-		defer resp.Body.Close()
-
-		if resp.StatusCode != http.StatusOK {
-			fmt.Printf("❌ HTTP error: %d %s\n", resp.StatusCode, resp.Status)
-			body, _ := io.ReadAll(resp.Body)
-			fmt.Printf("Response body: %s\n", string(body))
-			break
-		}
-
-		body, err := io.ReadAll(resp.Body)
-		if err != nil {
-			fmt.Printf("❌ Error reading response body: %v\n", err)
-			break
-		}
-
-		// log the raw response for debugging
-		if s.verbose {
-			fmt.Printf("GraphQL Response: %s\n", string(body))
+			return reviews, afterToken, fmt.Errorf("error marshaling GraphQL payload: %v", err)
 		}
 
 		var graphqlResp GraphQLResponse
-		if err := json.Unmarshal(body, &graphqlResp); err != nil {
-			fmt.Printf("❌ Error parsing GraphQL response: %v\n", err)
-			break
-		}
-
-		if len(graphqlResp.Errors) > 0 {
-			// Check if errors are just authorization errors for non-critical fields
-			criticalError := false
-			authErrorCount := 0
-			for _, err := range graphqlResp.Errors {
-				if err.ErrorType != "Unauthorized" {
-					criticalError = true
-					break
-				} else {
-					authErrorCount++
-				}
-			}
-			if criticalError {
-				fmt.Printf("❌ Critical GraphQL errors: %v\n", graphqlResp.Errors)
-				break
-			} else {
-				if s.verbose {
-					fmt.Printf("⚠️ %d non-critical authorization errors (expected for public API)\n", authErrorCount)
-				}
-			}
+		err = s.withRetry(ctx, "GraphQL page", func() error {
+			var err error
+			graphqlResp, err = s.fetchGraphQLPage(ctx, graphqlURL, headers, jsonPayload)
+			return err
+		})
+		if err != nil {
+			return reviews, afterToken, fmt.Errorf("review fetch stopped after %d reviews: %w", len(reviews), err)
 		}
 
 		if len(graphqlResp.Data.GetReviews.Edges) == 0 {
 			fmt.Printf("📊 No more reviews found. Total available: %d, Fetched: %d\n",
 				graphqlResp.Data.GetReviews.TotalCount, len(reviews))
+			afterToken = ""
 			break
 		}
 
@@ -519,12 +661,18 @@ func (s *goodreadsScraper) FetchReviewsGraphQL(workID string, maxReviews int, la
 		}
 
 		batchProcessed := 0
+		caughtUp := false
 		for _, edge := range graphqlResp.Data.GetReviews.Edges {
 			if len(reviews) >= maxReviews {
 				break
 			}
 
 			reviewData := s.extractReviewFromGraphQL(edge.Node, bookMetadata)
+			reviewData.FetchedLanguage = language
+			if filters.Known != nil && filters.Known.Seen(reviewData) {
+				caughtUp = true
+				break
+			}
 			if reviewData.ReviewID != "" {
 				reviews = append(reviews, reviewData)
 				batchProcessed++
@@ -536,6 +684,15 @@ func (s *goodreadsScraper) FetchReviewsGraphQL(workID string, maxReviews int, la
 			fmt.Printf("✅ Processed %d reviews from this batch\n", batchProcessed)
 		}
 
+		// Older reviews were saved by an earlier run
+		if caughtUp {
+			if s.verbose {
+				fmt.Printf("⏹️ Reached reviews saved by an earlier run after %d new reviews\n", len(reviews))
+			}
+			afterToken = ""
+			break
+		}
+
 		// Check for next page
 		afterToken = graphqlResp.Data.GetReviews.PageInfo.NextPageToken
 		if afterToken == "" {
@@ -547,11 +704,9 @@ func (s *goodreadsScraper) FetchReviewsGraphQL(workID string, maxReviews int, la
 				len(reviews), maxReviews, float64(len(reviews))/float64(maxReviews)*100)
 		}
 
-		if afterToken != "" {
-			if s.verbose {
-				fmt.Printf("🔄 Continuing to next page...\n")
-			}
-			time.Sleep(1 * time.Second) // Rate limiting
+		// Pacing between pages is handled by the GraphQL rate limiter
+		if s.verbose {
+			fmt.Printf("🔄 Continuing to next page...\n")
 		}
 	}
 
@@ -559,22 +714,100 @@ func (s *goodreadsScraper) FetchReviewsGraphQL(workID string, maxReviews int, la
 	if s.verbose {
 		fmt.Printf("🎉 GraphQL fetch completed! Retrieved %d reviews total\n", finalCount)
 	}
-	return reviews[:finalCount], nil
+	return reviews[:finalCount], afterToken, nil
+}
+
+// fetchGraphQLPage performs a single GraphQL request and decodes its response.
+// Critical GraphQL errors are returned as typed errors so throttling can be retried.
+func (s *goodreadsScraper) fetchGraphQLPage(ctx context.Context, graphqlURL string, headers map[string]string, payload []byte) (GraphQLResponse, error) {
+	var graphqlResp GraphQLResponse
+
+	if err := wait(ctx, s.graphqlLimiter); err != nil {
+		return graphqlResp, err
+	}
+
+	req, err := http.NewRequestWithContext(ctx, "POST", graphqlURL, bytes.NewReader(payload))
+	if err != nil {
+		return graphqlResp, fmt.Errorf("error creating request: %v", err)
+	}
+
+	// Set headers
+	for key, value := range headers {
+		req.Header.Set(key, value)
+	}
+
+	resp, err := s.client.Do(req)
+	if err != nil {
+		return graphqlResp, wrapRequestError(ctx, graphqlURL, err)
+	}
+	defer resp.Body.Close()
+
+	if err := checkResponse(resp, graphqlURL); err != nil {
+		// AppSync rejects bad API keys with 403
+		var blocked *BlockedError
+		if errors.As(err, &blocked) && blocked.Reason == "forbidden" {
+			err = &AuthError{Message: fmt.Sprintf("GraphQL endpoint returned %d", blocked.StatusCode)}
+		}
+		return graphqlResp, err
+	}
+
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return graphqlResp, wrapRequestError(ctx, graphqlURL, err)
+	}
+
+	// log the raw response for debugging
+	if s.verbose {
+		fmt.Printf("GraphQL Response: %s\n", string(body))
+	}
+
+	if err := json.Unmarshal(body, &graphqlResp); err != nil {
+		return graphqlResp, &SchemaChangedError{What: "GraphQL response", Err: err}
+	}
+
+	if len(graphqlResp.Errors) > 0 {
+		// Check if errors are just authorization errors for non-critical fields
+		authErrorCount := 0
+		for _, err := range graphqlResp.Errors {
+			if err.ErrorType != "Unauthorized" {
+				return graphqlResp, classifyGraphQLErrors(graphqlResp)
+			}
+			authErrorCount++
+		}
+		if s.verbose {
+			fmt.Printf("⚠️ %d non-critical authorization errors (expected for public API)\n", authErrorCount)
+		}
+	}
+
+	return graphqlResp, nil
+}
+
+// classifyGraphQLErrors maps critical GraphQL errors to a typed error
+func classifyGraphQLErrors(resp GraphQLResponse) error {
+	messages := make([]string, 0, len(resp.Errors))
+	for _, e := range resp.Errors {
+		messages = append(messages, fmt.Sprintf("%s: %s", e.ErrorType, e.Message))
+	}
+	summary := strings.Join(messages, "; ")
+
+	for _, e := range resp.Errors {
+		switch e.ErrorType {
+		case "UnauthorizedException":
+			return &AuthError{Message: summary}
+		case "Throttled", "ThrottlingException", "LimitExceededException":
+			return &RateLimitedError{URL: "GraphQL"}
+		}
+	}
+	return &SchemaChangedError{What: "GraphQL errors", Err: errors.New(summary)}
 }
 
 // extractReviewFromGraphQL converts GraphQL review node to models.Review
 func (s *goodreadsScraper) extractReviewFromGraphQL(node ReviewNode, bookMetadata models.BookMetadata) models.Review {
-	ratingStr := ""
+	// Unrated reviews come back with rating 0
+	var rating *int
 	if node.Rating > 0 {
-		ratingStr = strconv.Itoa(node.Rating)
-	}
-
-	// Format date - convert from epoch timestamp
-	reviewDate := ""
-	if node.CreatedAt > 0 {
-		// Convert from milliseconds epoch to time
-		createdTime := time.Unix(int64(node.CreatedAt/1000), 0)
-		reviewDate = createdTime.Format("2006-01-02")
+		r := node.Rating
+		rating = &r
 	}
 
 	// Clean up review text (remove HTML tags)
@@ -585,15 +818,48 @@ func (s *goodreadsScraper) extractReviewFromGraphQL(node ReviewNode, bookMetadat
 		reviewText = re.ReplaceAllString(reviewText, "")
 	}
 
+	var tags []models.ReviewTag
+	for _, tagging := range node.Shelving.Taggings {
+		if tagging.Tag.Name != "" {
+			tags = append(tags, models.ReviewTag{Name: tagging.Tag.Name, URL: tagging.Tag.WebURL})
+		}
+	}
+
+	reviewerID := ""
+	if node.Creator.ID > 0 {
+		reviewerID = strconv.Itoa(node.Creator.ID)
+	}
+
+	detected := langdetect.Detect(reviewText)
+
 	return models.Review{
+		WorkID:       bookMetadata.WorkID,
 		BookURL:      bookMetadata.URL,
 		BookTitle:    bookMetadata.Title,
 		ReviewID:     node.ID,
+		ReviewURL:    node.Shelving.WebURL,
 		ReviewerName: node.Creator.Name,
-		Rating:       ratingStr,
+		Rating:       rating,
 		ReviewText:   reviewText,
-		ReviewDate:   reviewDate,
-		Language:     "", // Language detection could be added here if needed
+
+		Language:           detected.Language,
+		LanguageConfidence: detected.Confidence,
+
+		CreatedAt:      parser.EpochMillisToTime(node.CreatedAt),
+		UpdatedAt:      parser.EpochMillisToTime(node.UpdatedAt),
+		LastRevisionAt: parser.EpochMillisToTime(node.LastRevisionAt),
+
+		ReviewerID:               reviewerID,
+		ReviewerURL:              node.Creator.WebURL,
+		ReviewerIsAuthor:         node.Creator.IsAuthor,
+		ReviewerFollowersCount:   node.Creator.FollowersCount,
+		ReviewerTextReviewsCount: node.Creator.TextReviewsCount,
+
+		LikeCount:    node.LikeCount,
+		CommentCount: node.CommentCount,
+		Spoiler:      node.SpoilerStatus,
+		Shelf:        node.Shelving.Shelf.Name,
+		Tags:         tags,
 	}
 }
 
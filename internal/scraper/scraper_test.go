@@ -1,8 +1,18 @@
 package scraper
 
 import (
+	"context"
 	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync/atomic"
 	"testing"
+	"time"
 
 	"github.com/rizkirmdhnnn/goodreadscrape/internal/models"
 )
@@ -181,8 +191,8 @@ func TestExtractReviewFromGraphQL(t *testing.T) {
 		t.Errorf("Expected ReviewerName 'Test Reviewer', got '%s'", review.ReviewerName)
 	}
 
-	if review.Rating != "4" {
-		t.Errorf("Expected Rating '4', got '%s'", review.Rating)
+	if review.Rating == nil || *review.Rating != 4 {
+		t.Errorf("Expected Rating 4, got %v", review.Rating)
 	}
 
 	if review.BookTitle != "Test Book" {
@@ -199,17 +209,29 @@ func TestExtractReviewFromGraphQL(t *testing.T) {
 		t.Errorf("Expected ReviewText '%s', got '%s'", expectedText, review.ReviewText)
 	}
 
-	// Test date formatting (allow for timezone differences)
-	expectedDates := []string{"2014-01-27", "2014-01-28"}
-	dateFound := false
-	for _, expectedDate := range expectedDates {
-		if review.ReviewDate == expectedDate {
-			dateFound = true
-			break
-		}
+	// Test language detection from the cleaned text
+	if review.Language != "en" || review.LanguageConfidence <= 0 {
+		t.Errorf("Expected Language 'en' with a confidence, got '%s' (%f)", review.Language, review.LanguageConfidence)
+	}
+
+	// Test timestamp conversion (UTC, millisecond precision)
+	expectedCreatedAt := time.Date(2014, 1, 27, 17, 19, 4, 0, time.UTC)
+	if !review.CreatedAt.Equal(expectedCreatedAt) || review.CreatedAt.Location() != time.UTC {
+		t.Errorf("Expected CreatedAt %s, got %s", expectedCreatedAt, review.CreatedAt)
 	}
-	if !dateFound {
-		t.Errorf("Expected ReviewDate to be one of %v, got '%s'", expectedDates, review.ReviewDate)
+	if !review.UpdatedAt.IsZero() {
+		t.Errorf("Expected zero UpdatedAt, got %s", review.UpdatedAt)
+	}
+}
+
+func TestExtractReviewFromGraphQLUnrated(t *testing.T) {
+	scraper := &goodreadsScraper{apiKey: "test"}
+	review := scraper.extractReviewFromGraphQL(ReviewNode{ID: "unrated", Rating: 0}, models.BookMetadata{})
+	if review.Rating != nil {
+		t.Errorf("Expected nil Rating for unrated review, got %d", *review.Rating)
+	}
+	if review.Language != "" {
+		t.Errorf("Expected no Language for a review without text, got '%s'", review.Language)
 	}
 }
 
@@ -231,3 +253,531 @@ func TestMinFunction(t *testing.T) {
 		}
 	}
 }
+
+func TestFetchReviewsGraphQLCancelled(t *testing.T) {
+	scraper := NewGoodreadsScraper("test", false)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	reviews, err := scraper.FetchReviewsGraphQL(ctx, "kca://work/test", 10, "en", models.BookMetadata{})
+	if !errors.Is(err, context.Canceled) {
+		t.Fatalf("Expected context.Canceled error, got %v", err)
+	}
+	if len(reviews) != 0 {
+		t.Errorf("Expected no reviews, got %d", len(reviews))
+	}
+}
+
+// fakeHTTPClient is a test double for HTTPClient that serves a fixed response
+type fakeHTTPClient struct {
+	statusCode int
+	body       string
+	requests   []*http.Request
+}
+
+func (c *fakeHTTPClient) Do(req *http.Request) (*http.Response, error) {
+	c.requests = append(c.requests, req)
+	return &http.Response{
+		StatusCode: c.statusCode,
+		Status:     http.StatusText(c.statusCode),
+		Header:     http.Header{},
+		Body:       io.NopCloser(strings.NewReader(c.body)),
+	}, nil
+}
+
+func TestExtractWorkIDUsesInjectedClient(t *testing.T) {
+	client := &fakeHTTPClient{
+		statusCode: http.StatusOK,
+		body:       `<script>{"work":{"__ref":"Work:kca://work/amzn1.gr.work.v1.abc"}}</script>`,
+	}
+	scraper := NewGoodreadsScraper("test", false, WithHTTPClient(client))
+
+	workID, err := scraper.ExtractWorkID(context.Background(), "https://www.goodreads.com/book/show/1")
+	if err != nil {
+		t.Fatalf("ExtractWorkID failed: %v", err)
+	}
+	if workID != "kca://work/amzn1.gr.work.v1.abc" {
+		t.Errorf("Unexpected work ID: %s", workID)
+	}
+
+	if len(client.requests) != 1 {
+		t.Fatalf("Expected 1 request through injected client, got %d", len(client.requests))
+	}
+	req := client.requests[0]
+	if req.URL.String() != "https://www.goodreads.com/book/show/1/reviews" {
+		t.Errorf("Unexpected request URL: %s", req.URL)
+	}
+	if req.Header.Get("User-Agent") == "" {
+		t.Error("Expected User-Agent header to be set")
+	}
+}
+
+func TestReviewsURLHonorsBaseURL(t *testing.T) {
+	tests := []struct {
+		name     string
+		baseURL  string
+		bookURL  string
+		expected string
+	}{
+		{
+			name:     "Default base URL",
+			bookURL:  "https://www.goodreads.com/book/show/1.Title/",
+			expected: "https://www.goodreads.com/book/show/1.Title/reviews",
+		},
+		{
+			name:     "Local fake server",
+			baseURL:  "http://127.0.0.1:8080/",
+			bookURL:  "https://www.goodreads.com/book/show/1.Title",
+			expected: "http://127.0.0.1:8080/book/show/1.Title/reviews",
+		},
+		{
+			name:     "Mirror with path prefix",
+			baseURL:  "https://mirror.example.com/goodreads",
+			bookURL:  "https://mirror.example.com/goodreads/book/show/1",
+			expected: "https://mirror.example.com/goodreads/book/show/1/reviews",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s := NewGoodreadsScraper("test", false, WithBaseURL(tt.baseURL)).(*goodreadsScraper)
+			got, err := s.reviewsURL(tt.bookURL)
+			if err != nil {
+				t.Fatalf("reviewsURL failed: %v", err)
+			}
+			if got != tt.expected {
+				t.Errorf("reviewsURL(%q) = %q, want %q", tt.bookURL, got, tt.expected)
+			}
+		})
+	}
+}
+
+func TestFetchReviewsGraphQLCustomEndpoint(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPost {
+			t.Errorf("Expected POST, got %s", r.Method)
+		}
+		if r.Header.Get("x-api-key") != "test" {
+			t.Errorf("Expected x-api-key header 'test', got %q", r.Header.Get("x-api-key"))
+		}
+		w.Write([]byte(`{"data":{"getReviews":{"totalCount":1,"edges":[{"node":{"id":"review-1","text":"Nice","rating":4,"creator":{"name":"Erma"}}}],"pageInfo":{"nextPageToken":""}}}}`))
+	}))
+	defer server.Close()
+
+	scraper := NewGoodreadsScraper("test", false, WithGraphQLURL(server.URL))
+	reviews, err := scraper.FetchReviewsGraphQL(context.Background(), "kca://work/test", 10, "", models.BookMetadata{Title: "Test Book"})
+	if err != nil {
+		t.Fatalf("FetchReviewsGraphQL failed: %v", err)
+	}
+	if len(reviews) != 1 || reviews[0].ReviewID != "review-1" {
+		t.Errorf("Unexpected reviews: %+v", reviews)
+	}
+}
+
+func TestFetchReviewsContinuesFromPageToken(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var req struct {
+			Variables struct {
+				Pagination struct {
+					After string `json:"after"`
+				} `json:"pagination"`
+			} `json:"variables"`
+		}
+		json.NewDecoder(r.Body).Decode(&req)
+
+		switch req.Variables.Pagination.After {
+		case "":
+			w.Write([]byte(`{"data":{"getReviews":{"edges":[{"node":{"id":"review-1"}}],"pageInfo":{"nextPageToken":"p2"}}}}`))
+		case "p2":
+			w.Write([]byte(`{"data":{"getReviews":{"edges":[{"node":{"id":"review-2"}}],"pageInfo":{"nextPageToken":"p3"}}}}`))
+		default:
+			w.Write([]byte(`{"data":{"getReviews":{"edges":[],"pageInfo":{}}}}`))
+		}
+	}))
+	defer server.Close()
+
+	s := NewGoodreadsScraper("test", false, WithGraphQLURL(server.URL)).(*goodreadsScraper)
+
+	reviews, next, err := s.fetchReviews(context.Background(), "kca://work/test", 1, models.Filters{After: "p2"}, models.BookMetadata{})
+	if err != nil {
+		t.Fatalf("fetchReviews failed: %v", err)
+	}
+	if len(reviews) != 1 || reviews[0].ReviewID != "review-2" {
+		t.Errorf("Expected review-2 from the saved page token, got %+v", reviews)
+	}
+	if next != "p3" {
+		t.Errorf("Expected next page token p3, got %q", next)
+	}
+
+	reviews, next, err = s.fetchReviews(context.Background(), "kca://work/test", 10, models.Filters{After: next}, models.BookMetadata{})
+	if err != nil {
+		t.Fatalf("fetchReviews failed: %v", err)
+	}
+	if len(reviews) != 0 || next != "" {
+		t.Errorf("Expected no reviews and no token once exhausted, got %d reviews and %q", len(reviews), next)
+	}
+}
+
+func TestFetchReviewsStopsAtKnownReviews(t *testing.T) {
+	// Newest first: two new reviews, then one saved by an earlier run
+	pages := map[string]string{
+		"":   `{"data":{"getReviews":{"edges":[{"node":{"id":"review-5","createdAt":1700000500000}},{"node":{"id":"review-4","createdAt":1700000400000}}],"pageInfo":{"nextPageToken":"p2"}}}}`,
+		"p2": `{"data":{"getReviews":{"edges":[{"node":{"id":"review-3","createdAt":1700000300000}},{"node":{"id":"review-2","createdAt":1700000200000}}],"pageInfo":{"nextPageToken":"p3"}}}}`,
+		"p3": `{"data":{"getReviews":{"edges":[],"pageInfo":{}}}}`,
+	}
+	requests := 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests++
+		var req struct {
+			Variables struct {
+				Filters struct {
+					Sort string `json:"sort"`
+				} `json:"filters"`
+				Pagination struct {
+					After string `json:"after"`
+				} `json:"pagination"`
+			} `json:"variables"`
+		}
+		json.NewDecoder(r.Body).Decode(&req)
+		if req.Variables.Filters.Sort != "NEWEST" {
+			t.Errorf("Expected sort NEWEST, got %q", req.Variables.Filters.Sort)
+		}
+		w.Write([]byte(pages[req.Variables.Pagination.After]))
+	}))
+	defer server.Close()
+
+	s := NewGoodreadsScraper("test", false, WithGraphQLURL(server.URL)).(*goodreadsScraper)
+	book := models.BookMetadata{WorkID: "kca://work/test"}
+
+	tests := []struct {
+		name     string
+		known    func(models.KnownReviews)
+		expected []string
+	}{
+		{
+			name:     "Known review ID",
+			known:    func(k models.KnownReviews) { k.Add(book.WorkID, "review-3", "", time.Time{}) },
+			expected: []string{"review-5", "review-4"},
+		},
+		{
+			name:     "Older than the latest known review",
+			known:    func(k models.KnownReviews) { k.Add(book.WorkID, "review-x", "", time.UnixMilli(1700000450000)) },
+			expected: []string{"review-5"},
+		},
+		{
+			name:     "Other work only",
+			known:    func(k models.KnownReviews) { k.Add("kca://work/other", "review-5", "", time.Time{}) },
+			expected: []string{"review-5", "review-4", "review-3", "review-2"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			known := models.KnownReviews{}
+			tt.known(known)
+			reviews, next, err := s.fetchReviews(context.Background(), book.WorkID, 100, models.Filters{Known: known}, book)
+			if err != nil {
+				t.Fatalf("fetchReviews failed: %v", err)
+			}
+			var ids []string
+			for _, review := range reviews {
+				ids = append(ids, review.ReviewID)
+			}
+			if strings.Join(ids, ",") != strings.Join(tt.expected, ",") {
+				t.Errorf("Expected %v, got %v", tt.expected, ids)
+			}
+			if next != "" {
+				t.Errorf("Expected no page token once caught up, got %q", next)
+			}
+		})
+	}
+
+	// Caught up on the first page, so the second page is never requested
+	requests = 0
+	known := models.KnownReviews{}
+	known.Add(book.WorkID, "review-4", "", time.Time{})
+	if _, _, err := s.fetchReviews(context.Background(), book.WorkID, 100, models.Filters{Known: known}, book); err != nil {
+		t.Fatalf("fetchReviews failed: %v", err)
+	}
+	if requests != 1 {
+		t.Errorf("Expected 1 request, got %d", requests)
+	}
+}
+
+func TestFetchReviewsInSeveralLanguages(t *testing.T) {
+	pages := map[string]string{
+		"id":    `{"data":{"getReviews":{"edges":[{"node":{"id":"id-1"}},{"node":{"id":"id-2"}}],"pageInfo":{"nextPageToken":"p2"}}}}`,
+		"id|p2": `{"data":{"getReviews":{"edges":[{"node":{"id":"id-3"}}],"pageInfo":{}}}}`,
+		"en":    `{"data":{"getReviews":{"edges":[{"node":{"id":"en-1"}}],"pageInfo":{}}}}`,
+	}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var req struct {
+			Variables struct {
+				Filters struct {
+					LanguageCode string `json:"languageCode"`
+				} `json:"filters"`
+				Pagination struct {
+					After string `json:"after"`
+				} `json:"pagination"`
+			} `json:"variables"`
+		}
+		json.NewDecoder(r.Body).Decode(&req)
+		key := req.Variables.Filters.LanguageCode
+		if req.Variables.Pagination.After != "" {
+			key += "|" + req.Variables.Pagination.After
+		}
+		page, ok := pages[key]
+		if !ok {
+			t.Errorf("Unexpected request for %q", key)
+		}
+		w.Write([]byte(page))
+	}))
+	defer server.Close()
+
+	s := NewGoodreadsScraper("test", false, WithGraphQLURL(server.URL)).(*goodreadsScraper)
+	book := models.BookMetadata{WorkID: "kca://work/test"}
+
+	tests := []struct {
+		name        string
+		maxReviews  int
+		perLanguage bool
+		after       string
+		expected    []string
+		next        string
+	}{
+		{"Every review", 100, false, "", []string{"id-1", "id-2", "id-3", "en-1"}, ""},
+		{"Overall limit within a language", 2, false, "", []string{"id-1", "id-2"}, "id|2|p2"},
+		{"Overall limit at the end of a language", 3, false, "", []string{"id-1", "id-2", "id-3"}, "en|0|"},
+		{"Continue from a cursor", 2, false, "id|2|p2", []string{"id-3", "en-1"}, ""},
+		{"Limit per language", 2, true, "", []string{"id-1", "id-2", "en-1"}, ""},
+		{"Continue a language's quota", 3, true, "id|2|p2", []string{"id-3", "en-1"}, ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			filters := models.Filters{Languages: []string{"id", "en"}, MaxPerLanguage: tt.perLanguage, After: tt.after}
+			reviews, next, err := s.fetchReviews(context.Background(), book.WorkID, tt.maxReviews, filters, book)
+			if err != nil {
+				t.Fatalf("fetchReviews failed: %v", err)
+			}
+			var ids []string
+			for _, review := range reviews {
+				ids = append(ids, review.ReviewID)
+				if language, _, _ := strings.Cut(review.ReviewID, "-"); review.FetchedLanguage != language {
+					t.Errorf("Expected review %s fetched in %s, got %q", review.ReviewID, language, review.FetchedLanguage)
+				}
+			}
+			if strings.Join(ids, ",") != strings.Join(tt.expected, ",") {
+				t.Errorf("Expected %v, got %v", tt.expected, ids)
+			}
+			if next != tt.next {
+				t.Errorf("Expected page token %q, got %q", tt.next, next)
+			}
+		})
+	}
+}
+
+func TestScrapeBookDataFetchesPageOnce(t *testing.T) {
+	var pageRequests atomic.Int32
+	mux := http.NewServeMux()
+	mux.HandleFunc("/book/show/1/reviews", func(w http.ResponseWriter, r *http.Request) {
+		pageRequests.Add(1)
+		w.Write([]byte(`<html><body>
+			<a data-testid="title">Test Book</a>
+			<span class="ContributorLink__name" data-testid="name">Test Author</span>
+			<script>{"work":{"__ref":"Work:kca://work/test"}}</script>
+		</body></html>`))
+	})
+	mux.HandleFunc("/graphql", func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`{"data":{"getReviews":{"totalCount":0,"edges":[],"pageInfo":{}}}}`))
+	})
+	server := httptest.NewServer(mux)
+	defer server.Close()
+
+	scraper := NewGoodreadsScraper("test", false, WithBaseURL(server.URL), WithGraphQLURL(server.URL+"/graphql"))
+	bookData, err := scraper.ScrapeBookData(context.Background(), "https://www.goodreads.com/book/show/1", 10, models.Filters{})
+	if err != nil {
+		t.Fatalf("ScrapeBookData failed: %v", err)
+	}
+
+	if got := pageRequests.Load(); got != 1 {
+		t.Errorf("Expected book page to be fetched once, got %d", got)
+	}
+	if bookData.Metadata.Title != "Test Book" {
+		t.Errorf("Expected title 'Test Book', got %q", bookData.Metadata.Title)
+	}
+}
+
+func TestParseWorkIDMissing(t *testing.T) {
+	_, err := ParseWorkID([]byte("<html><body>nothing here</body></html>"))
+	if ErrorCategory(err) != CategorySchemaChanged {
+		t.Errorf("Expected schema changed error, got %v", err)
+	}
+
+	_, err = ParseWorkID([]byte("<html><body>Please solve this CAPTCHA</body></html>"))
+	if ErrorCategory(err) != CategoryBlocked {
+		t.Errorf("Expected blocked error, got %v", err)
+	}
+}
+
+func TestParseBookMetadataMalformedState(t *testing.T) {
+	tests := []struct {
+		name string
+		html string
+	}{
+		{"Invalid JSON", `<html><body><script id="__NEXT_DATA__" type="application/json">{"props": {"pageProps": </script><h1 data-testid="bookTitle">Title</h1></body></html>`},
+		{"Missing Apollo state", `<html><body><script id="__NEXT_DATA__" type="application/json">{"props": {"pageProps": {}}}</script></body></html>`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := ParseBookMetadata([]byte(tt.html), "https://www.goodreads.com/book/show/1")
+			if ErrorCategory(err) != CategorySchemaChanged {
+				t.Errorf("Expected schema changed error, got %v", err)
+			}
+		})
+	}
+}
+
+func TestParseBookMetadataPrefersPageState(t *testing.T) {
+	html, err := os.ReadFile(filepath.Join("..", "parser", "testdata", "book_page.html"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	metadata, err := ParseBookMetadata(html, "https://www.goodreads.com/book/show/1362193.Laskar_Pelangi")
+	if err != nil {
+		t.Fatalf("ParseBookMetadata failed: %v", err)
+	}
+
+	if metadata.Title != "Laskar Pelangi" || metadata.Author != "Andrea Hirata" {
+		t.Errorf("Expected state values, got title %q author %q", metadata.Title, metadata.Author)
+	}
+	if metadata.WorkID != "kca://work/amzn1.gr.work.v1.LPW" || metadata.BookID != "1362193" {
+		t.Errorf("Unexpected IDs: work %q book %q", metadata.WorkID, metadata.BookID)
+	}
+	if metadata.AverageRating != 4.23 || metadata.RatingsCount != 88517 || metadata.TextReviewsCount != 6433 {
+		t.Errorf("Unexpected stats: %+v", metadata)
+	}
+	if len(metadata.Contributors) != 2 || metadata.Contributors[1].Role != "Translator" {
+		t.Errorf("Unexpected contributors: %+v", metadata.Contributors)
+	}
+	if metadata.ISBN13 != "9789793062792" || metadata.PageCount != 529 || metadata.Language != "Indonesian" {
+		t.Errorf("Unexpected edition details: %+v", metadata)
+	}
+	if !metadata.EditionDate.Equal(time.Date(2008, 1, 1, 8, 0, 0, 0, time.UTC)) {
+		t.Errorf("Unexpected edition date: %s", metadata.EditionDate)
+	}
+	if metadata.FirstPublished.Year() != 2005 {
+		t.Errorf("Unexpected first published date: %s", metadata.FirstPublished)
+	}
+	if metadata.RatingDistribution != [5]int{1208, 2563, 11397, 29860, 43489} {
+		t.Errorf("Unexpected rating distribution: %v", metadata.RatingDistribution)
+	}
+	if metadata.SeriesName != "Tetralogi Laskar Pelangi" || metadata.SeriesPosition != "1" || len(metadata.Genres) != 3 {
+		t.Errorf("Unexpected series/genres: %q %q %v", metadata.SeriesName, metadata.SeriesPosition, metadata.Genres)
+	}
+}
+
+func TestExtractReviewFromGraphQLEngagement(t *testing.T) {
+	nodeJSON := `{
+		"id": "kca://review:goodreads/amzn1.gr.review:goodreads.v1.abc",
+		"creator": {
+			"id": 10113893,
+			"isAuthor": true,
+			"followersCount": 7,
+			"textReviewsCount": 54,
+			"name": "Erma",
+			"webUrl": "https://www.goodreads.com/user/show/10113893-erma"
+		},
+		"updatedAt": 1761546360777,
+		"createdAt": 1390843144000,
+		"spoilerStatus": true,
+		"lastRevisionAt": 1390843257000,
+		"text": "Bagus",
+		"rating": 5,
+		"shelving": {
+			"shelf": {"name": "read", "displayName": "Read"},
+			"taggings": [
+				{"tag": {"name": "favorites", "webUrl": "https://www.goodreads.com/review/list/10113893?shelf=favorites"}},
+				{"tag": {"name": "indonesian", "webUrl": "https://www.goodreads.com/review/list/10113893?shelf=indonesian"}}
+			],
+			"webUrl": "https://www.goodreads.com/review/show/836115237"
+		},
+		"likeCount": 80,
+		"commentCount": 16
+	}`
+
+	var node ReviewNode
+	if err := json.Unmarshal([]byte(nodeJSON), &node); err != nil {
+		t.Fatalf("Failed to parse review node: %v", err)
+	}
+
+	scraper := &goodreadsScraper{apiKey: "test"}
+	review := scraper.extractReviewFromGraphQL(node, models.BookMetadata{WorkID: "kca://work/test"})
+
+	if review.WorkID != "kca://work/test" {
+		t.Errorf("Expected WorkID 'kca://work/test', got %q", review.WorkID)
+	}
+	if review.ReviewerID != "10113893" || review.ReviewerURL != "https://www.goodreads.com/user/show/10113893-erma" {
+		t.Errorf("Unexpected reviewer identity: %q %q", review.ReviewerID, review.ReviewerURL)
+	}
+	if !review.ReviewerIsAuthor || review.ReviewerFollowersCount != 7 || review.ReviewerTextReviewsCount != 54 {
+		t.Errorf("Unexpected reviewer stats: %+v", review)
+	}
+	if review.LikeCount != 80 || review.CommentCount != 16 || !review.Spoiler {
+		t.Errorf("Unexpected engagement: likes %d comments %d spoiler %v", review.LikeCount, review.CommentCount, review.Spoiler)
+	}
+	if review.Shelf != "read" || review.ReviewURL != "https://www.goodreads.com/review/show/836115237" {
+		t.Errorf("Unexpected shelving: %q %q", review.Shelf, review.ReviewURL)
+	}
+	if len(review.Tags) != 2 || review.Tags[0].Name != "favorites" || review.Tags[1].Name != "indonesian" {
+		t.Errorf("Unexpected tags: %+v", review.Tags)
+	}
+	if review.UpdatedAt.UnixMilli() != 1761546360777 || review.LastRevisionAt.UnixMilli() != 1390843257000 {
+		t.Errorf("Unexpected UpdatedAt %s / LastRevisionAt %s", review.UpdatedAt, review.LastRevisionAt)
+	}
+}
+
+func TestFetchReviewsStopsAtKnownReviewsPerLanguage(t *testing.T) {
+	// The newest saved Indonesian review is older than the newest English one
+	pages := map[string]string{
+		"id": `{"data":{"getReviews":{"edges":[{"node":{"id":"id-3","createdAt":1700000300000}},{"node":{"id":"id-2","createdAt":1700000200000}}],"pageInfo":{}}}}`,
+		"en": `{"data":{"getReviews":{"edges":[{"node":{"id":"en-2","createdAt":1700000600000}},{"node":{"id":"en-1","createdAt":1700000500000}}],"pageInfo":{}}}}`,
+	}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var req struct {
+			Variables struct {
+				Filters struct {
+					LanguageCode string `json:"languageCode"`
+				} `json:"filters"`
+			} `json:"variables"`
+		}
+		json.NewDecoder(r.Body).Decode(&req)
+		w.Write([]byte(pages[req.Variables.Filters.LanguageCode]))
+	}))
+	defer server.Close()
+
+	s := NewGoodreadsScraper("test", false, WithGraphQLURL(server.URL)).(*goodreadsScraper)
+	book := models.BookMetadata{WorkID: "kca://work/test"}
+
+	known := models.KnownReviews{}
+	known.Add(book.WorkID, "id-x", "id", time.UnixMilli(1700000250000))
+	known.Add(book.WorkID, "en-x", "en", time.UnixMilli(1700000550000))
+	filters := models.Filters{Languages: []string{"id", "en"}, Known: known}
+	reviews, next, err := s.fetchReviews(context.Background(), book.WorkID, 100, filters, book)
+	if err != nil {
+		t.Fatalf("fetchReviews failed: %v", err)
+	}
+	var ids []string
+	for _, review := range reviews {
+		ids = append(ids, review.ReviewID)
+	}
+	if expected := "id-3,en-2"; strings.Join(ids, ",") != expected {
+		t.Errorf("Expected %s, each language stopping at its own saved reviews, got %v", expected, ids)
+	}
+	if next != "" {
+		t.Errorf("Expected no page token once caught up, got %q", next)
+	}
+}
@@ -0,0 +1,237 @@
+package storage
+
+import (
+	"bufio"
+	"compress/gzip"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	"github.com/klauspost/compress/zstd"
+)
+
+// Compression names a stream compression applied to file-based outputs
+type Compression string
+
+// Supported compressions
+const (
+	CompressionNone Compression = ""
+	CompressionGzip Compression = "gzip"
+	CompressionZstd Compression = "zstd"
+)
+
+// ParseCompression validates a compression name. An empty name or "none"
+// disables compression.
+func ParseCompression(name string) (Compression, error) {
+	switch strings.ToLower(name) {
+	case "", "none":
+		return CompressionNone, nil
+	case "gzip", "gz":
+		return CompressionGzip, nil
+	case "zstd", "zst":
+		return CompressionZstd, nil
+	default:
+		return "", fmt.Errorf("unknown compression %q (use gzip, zstd or none)", name)
+	}
+}
+
+// Extension returns the file extension, including the dot, that marks the
+// compression, or an empty string for none
+func (c Compression) Extension() string {
+	switch c {
+	case CompressionGzip:
+		return ".gz"
+	case CompressionZstd:
+		return ".zst"
+	default:
+		return ""
+	}
+}
+
+// SplitCompression splits a compression extension off path, e.g.
+// results/reviews.csv.gz -> results/reviews.csv, gzip
+func SplitCompression(path string) (string, Compression) {
+	for _, c := range []Compression{CompressionGzip, CompressionZstd} {
+		ext := c.Extension()
+		if len(path) > len(ext) && strings.EqualFold(path[len(path)-len(ext):], ext) {
+			return path[:len(path)-len(ext)], c
+		}
+	}
+	return path, CompressionNone
+}
+
+// writeCompressed writes data to w as one complete gzip member or zstd frame.
+// Both formats allow members to be concatenated, so each batch appended this
+// way leaves a file that standard tools decompress as a single stream.
+func writeCompressed(w io.Writer, c Compression, data string) error {
+	var encoder io.WriteCloser
+	switch c {
+	case CompressionNone:
+		_, err := io.WriteString(w, data)
+		return err
+	case CompressionGzip:
+		encoder = gzip.NewWriter(w)
+	case CompressionZstd:
+		zw, err := zstd.NewWriter(w, zstd.WithEncoderConcurrency(1))
+		if err != nil {
+			return err
+		}
+		encoder = zw
+	default:
+		return fmt.Errorf("unknown compression %q", c)
+	}
+
+	if _, err := io.WriteString(encoder, data); err != nil {
+		encoder.Close()
+		return err
+	}
+	return encoder.Close()
+}
+
+// zstdReadCloser adapts a zstd decoder, whose Close returns nothing, to
+// io.ReadCloser
+type zstdReadCloser struct {
+	*zstd.Decoder
+}
+
+func (r zstdReadCloser) Close() error {
+	r.Decoder.Close()
+	return nil
+}
+
+// newDecompressor returns a reader of the decompressed contents of r
+func newDecompressor(r io.Reader, c Compression) (io.ReadCloser, error) {
+	switch c {
+	case CompressionNone:
+		return io.NopCloser(r), nil
+	case CompressionGzip:
+		return gzip.NewReader(r)
+	case CompressionZstd:
+		zr, err := zstd.NewReader(r, zstd.WithDecoderConcurrency(1))
+		if err != nil {
+			return nil, err
+		}
+		return zstdReadCloser{zr}, nil
+	default:
+		return nil, fmt.Errorf("unknown compression %q", c)
+	}
+}
+
+// recoverCompressed validates an existing compressed file and returns the
+// offset just past its last complete gzip member or zstd frame. Every batch
+// is written as its own member, so an interrupted write leaves at most one
+// truncated member at the end. Corruption anywhere else is an error.
+func recoverCompressed(file *os.File, size int64, c Compression) (int64, error) {
+	switch c {
+	case CompressionGzip:
+		return gzipEnd(io.NewSectionReader(file, 0, size))
+	case CompressionZstd:
+		return zstdEnd(file, size)
+	default:
+		return size, nil
+	}
+}
+
+// countingReader counts the bytes handed out. It implements io.ByteReader so
+// that gzip and flate read exactly what they consume rather than buffering
+// ahead, which makes the count a member boundary after each member.
+type countingReader struct {
+	r *bufio.Reader
+	n int64
+}
+
+func (c *countingReader) Read(p []byte) (int, error) {
+	n, err := c.r.Read(p)
+	c.n += int64(n)
+	return n, err
+}
+
+func (c *countingReader) ReadByte() (byte, error) {
+	b, err := c.r.ReadByte()
+	if err == nil {
+		c.n++
+	}
+	return b, err
+}
+
+// gzipEnd decompresses r member by member and returns the offset just past
+// the last complete member
+func gzipEnd(r io.Reader) (int64, error) {
+	counter := &countingReader{r: bufio.NewReader(r)}
+
+	var zr gzip.Reader
+	var good int64
+	for {
+		if err := zr.Reset(counter); err != nil {
+			if err == io.EOF && counter.n == good {
+				return good, nil
+			}
+			return truncatedEnd(good, err, "gzip")
+		}
+		zr.Multistream(false)
+		if _, err := io.Copy(io.Discard, &zr); err != nil {
+			return truncatedEnd(good, err, "gzip")
+		}
+		good = counter.n
+	}
+}
+
+// zstdEnd walks the zstd frames of file without decompressing them and
+// returns the offset just past the last complete frame
+func zstdEnd(file *os.File, size int64) (int64, error) {
+	var good int64
+	buf := make([]byte, zstd.HeaderMaxSize)
+	block := make([]byte, 3)
+	for good < size {
+		n, err := file.ReadAt(buf, good)
+		if err != nil && err != io.EOF {
+			return 0, fmt.Errorf("failed to read output file: %w", err)
+		}
+
+		var header zstd.Header
+		if err := header.Decode(buf[:n]); err != nil {
+			return truncatedEnd(good, err, "zstd")
+		}
+
+		end := good + int64(header.HeaderSize)
+		if header.Skippable {
+			end += int64(header.SkippableSize)
+		} else {
+			for last := false; !last; {
+				if _, err := file.ReadAt(block, end); err != nil {
+					return truncatedEnd(good, io.ErrUnexpectedEOF, "zstd")
+				}
+				bh := uint32(block[0]) | uint32(block[1])<<8 | uint32(block[2])<<16
+				last = bh&1 != 0
+				blockSize := int64(bh >> 3)
+				switch (bh >> 1) & 3 {
+				case 1: // RLE blocks store a single byte
+					blockSize = 1
+				case 3:
+					return 0, fmt.Errorf("corrupt zstd stream at offset %d", end)
+				}
+				end += 3 + blockSize
+			}
+			if header.HasCheckSum {
+				end += 4
+			}
+		}
+
+		if end > size {
+			return truncatedEnd(good, io.ErrUnexpectedEOF, "zstd")
+		}
+		good = end
+	}
+	return good, nil
+}
+
+// truncatedEnd returns good if err shows the stream was cut short, which is
+// what an interrupted write leaves behind, and an error otherwise
+func truncatedEnd(good int64, err error, name string) (int64, error) {
+	if errors.Is(err, io.ErrUnexpectedEOF) || errors.Is(err, io.EOF) {
+		return good, nil
+	}
+	return 0, fmt.Errorf("corrupt %s stream after offset %d: %w", name, good, err)
+}
@@ -0,0 +1,163 @@
+package storage
+
+import (
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/rizkirmdhnnn/goodreadscrape/internal/models"
+)
+
+// readCompressed returns the decompressed contents of a file
+func readCompressed(t *testing.T, path string, c Compression) string {
+	t.Helper()
+
+	file, err := os.Open(path)
+	if err != nil {
+		t.Fatalf("Failed to open output file: %v", err)
+	}
+	defer file.Close()
+
+	decompressor, err := newDecompressor(file, c)
+	if err != nil {
+		t.Fatalf("Failed to decompress output file: %v", err)
+	}
+	defer decompressor.Close()
+
+	content, err := io.ReadAll(decompressor)
+	if err != nil {
+		t.Fatalf("Failed to decompress output file: %v", err)
+	}
+	return string(content)
+}
+
+func TestParseCompression(t *testing.T) {
+	tests := []struct {
+		name     string
+		expected Compression
+		wantErr  bool
+	}{
+		{"", CompressionNone, false},
+		{"none", CompressionNone, false},
+		{"GZIP", CompressionGzip, false},
+		{"zst", CompressionZstd, false},
+		{"bzip2", "", true},
+	}
+
+	for _, tt := range tests {
+		got, err := ParseCompression(tt.name)
+		if (err != nil) != tt.wantErr {
+			t.Errorf("ParseCompression(%q) error = %v, wantErr %v", tt.name, err, tt.wantErr)
+		}
+		if got != tt.expected {
+			t.Errorf("ParseCompression(%q) = %q, want %q", tt.name, got, tt.expected)
+		}
+	}
+}
+
+func TestCompressedStorage_AppendsBatches(t *testing.T) {
+	for _, c := range []Compression{CompressionGzip, CompressionZstd} {
+		t.Run(string(c), func(t *testing.T) {
+			dir := t.TempDir()
+			batches := [][]models.Review{
+				{{ReviewID: "r1", WorkID: "w1", ReviewText: "first"}},
+				{{ReviewID: "r2", WorkID: "w1", ReviewText: "second"}, {ReviewID: "r3", WorkID: "w1"}},
+			}
+
+			csvPath := filepath.Join(dir, "reviews.csv"+c.Extension())
+			jsonlPath := filepath.Join(dir, "reviews.jsonl"+c.Extension())
+			for _, batch := range batches {
+				// A new storage per batch also validates the existing file each time
+				if err := NewCSVStorage().SaveReviews(batch, csvPath); err != nil {
+					t.Fatalf("CSV SaveReviews failed: %v", err)
+				}
+				if err := NewJSONLStorage().SaveReviews(batch, jsonlPath); err != nil {
+					t.Fatalf("JSONL SaveReviews failed: %v", err)
+				}
+			}
+
+			lines := strings.Split(strings.TrimSuffix(readCompressed(t, csvPath, c), "\n"), "\n")
+			if len(lines) != 4 {
+				t.Fatalf("Expected header and 3 CSV rows, got %d lines: %q", len(lines), lines)
+			}
+			if lines[0] != strings.Join(DefaultReviewColumns(), ",") {
+				t.Errorf("Expected header first, got %q", lines[0])
+			}
+			if !strings.Contains(lines[3], ",r3,") {
+				t.Errorf("Expected r3 last, got %q", lines[3])
+			}
+
+			jsonLines := strings.Split(strings.TrimSuffix(readCompressed(t, jsonlPath, c), "\n"), "\n")
+			if len(jsonLines) != 3 {
+				t.Errorf("Expected 3 JSON lines, got %d", len(jsonLines))
+			}
+		})
+	}
+}
+
+func TestCompressedStorage_TrimsTruncatedMember(t *testing.T) {
+	for _, c := range []Compression{CompressionGzip, CompressionZstd} {
+		t.Run(string(c), func(t *testing.T) {
+			dir := t.TempDir()
+
+			for _, format := range []Format{FormatCSV, FormatJSONL} {
+				path := filepath.Join(dir, "reviews"+format.Extension()+c.Extension())
+				store, _ := New(format)
+
+				if err := store.SaveReviews([]models.Review{{ReviewID: "r1"}}, path); err != nil {
+					t.Fatalf("SaveReviews failed: %v", err)
+				}
+				info, _ := os.Stat(path)
+				complete := info.Size()
+				if err := store.SaveReviews([]models.Review{{ReviewID: "r2", ReviewText: strings.Repeat("lost ", 50)}}, path); err != nil {
+					t.Fatalf("SaveReviews failed: %v", err)
+				}
+
+				// Simulate a crash part way through writing the second member
+				if err := os.Truncate(path, complete+10); err != nil {
+					t.Fatal(err)
+				}
+
+				resumed, _ := New(format)
+				if err := resumed.SaveReviews([]models.Review{{ReviewID: "r3"}}, path); err != nil {
+					t.Fatalf("%s: SaveReviews after crash failed: %v", format, err)
+				}
+
+				content := readCompressed(t, path, c)
+				if !strings.Contains(content, "r1") || !strings.Contains(content, "r3") || strings.Contains(content, "lost") {
+					t.Errorf("%s: expected r1 and r3 without the truncated batch, got %q", format, content)
+				}
+			}
+		})
+	}
+}
+
+func TestCompressedCSV_RejectsForeignHeader(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "reviews.csv.gz")
+
+	file, err := os.Create(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := writeCompressed(file, CompressionGzip, "id,text\n1,hello\n"); err != nil {
+		t.Fatal(err)
+	}
+	file.Close()
+
+	if err := NewCSVStorage().SaveReviews([]models.Review{{ReviewID: "r1"}}, path); err == nil {
+		t.Error("Expected error for a compressed file with a different header")
+	}
+}
+
+func TestCompressedStorage_RejectsPlainFile(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "reviews.jsonl.zst")
+	if err := os.WriteFile(path, []byte("{\"review_id\":\"r0\"}\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := NewJSONLStorage().SaveReviews([]models.Review{{ReviewID: "r1"}}, path); err == nil {
+		t.Error("Expected error for an uncompressed file with a .zst extension")
+	}
+}
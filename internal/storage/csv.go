@@ -3,22 +3,137 @@ package storage
 import (
 	"encoding/csv"
 	"fmt"
+	"io"
 	"os"
 	"path/filepath"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
 
 	"github.com/rizkirmdhnnn/goodreadscrape/internal/models"
 )
 
 // CSVStorage implements Storage interface for CSV output
-type CSVStorage struct{}
+type CSVStorage struct {
+	opts options
+
+	header  []string
+	columns []reviewColumn
+
+	mu      sync.Mutex
+	checked map[string]int     // Columns of files whose header and tail were validated
+	books   map[string]csvBook // Books saved so far by work ID, for book columns in the reviews file
+}
 
 // NewCSVStorage creates a new CSV storage instance
-func NewCSVStorage() Storage {
-	return &CSVStorage{}
+func NewCSVStorage(opts ...Option) Storage {
+	s := &CSVStorage{
+		opts:    newOptions(opts),
+		checked: make(map[string]int),
+		books:   make(map[string]csvBook),
+	}
+
+	s.header = s.opts.csvColumns
+	if len(s.header) == 0 {
+		s.header = DefaultReviewColumns()
+	}
+	for _, name := range s.header {
+		column, ok := lookupReviewColumn(name)
+		if !ok {
+			// Unknown names are rejected by ValidateReviewColumns; keep the column empty
+			column = func(models.Review, csvBook, TimeFormat) string { return "" }
+		}
+		s.columns = append(s.columns, column)
+	}
+	return s
 }
 
-// SaveReviews saves reviews to a CSV file
+// BooksPath returns the companion books file for a reviews output path,
+// e.g. results/reviews.csv -> results/reviews_books.csv and
+// results/reviews.csv.gz -> results/reviews_books.csv.gz
+func BooksPath(reviewsPath string) string {
+	path, _ := SplitCompression(reviewsPath)
+	ext := filepath.Ext(path)
+	return strings.TrimSuffix(path, ext) + "_books" + ext + reviewsPath[len(path):]
+}
+
+// SaveReviews saves reviews to a CSV file, with the configured columns
 func (s *CSVStorage) SaveReviews(reviews []models.Review, outputPath string) error {
+	s.mu.Lock()
+	books := make(map[string]csvBook)
+	for _, review := range reviews {
+		if book, ok := s.books[review.WorkID]; ok {
+			books[review.WorkID] = book
+		}
+	}
+	s.mu.Unlock()
+
+	records := make([][]string, 0, len(reviews))
+	for _, review := range reviews {
+		book := books[review.WorkID]
+		record := make([]string, len(s.columns))
+		for i, column := range s.columns {
+			record[i] = column(review, book, s.opts.timeFormat)
+		}
+		records = append(records, record)
+	}
+
+	return s.appendCSV(outputPath, s.header, len(s.opts.csvColumns) == 0, records)
+}
+
+// rememberBook keeps a book so that its reviews can carry book columns
+func (s *CSVStorage) rememberBook(bookData models.BookData) {
+	if bookData.Metadata.WorkID == "" {
+		return
+	}
+	s.mu.Lock()
+	s.books[bookData.Metadata.WorkID] = csvBook{
+		Metadata:       bookData.Metadata,
+		ScrapedAt:      bookData.ScrapedAt,
+		ReviewsFetched: bookData.Fetched(),
+	}
+	s.mu.Unlock()
+}
+
+// RememberBook keeps a book whose row is already in the books file, so that
+// its reviews can carry book columns
+func (s *CSVStorage) RememberBook(bookData models.BookData, outputPath string) {
+	s.rememberBook(bookData)
+}
+
+// SaveBookData saves one row of book metadata to a CSV file. Rows are keyed by
+// WorkID and BookURL so they can be joined with the reviews file.
+func (s *CSVStorage) SaveBookData(bookData models.BookData, outputPath string) error {
+	book := csvBook{
+		Metadata:       bookData.Metadata,
+		ScrapedAt:      bookData.ScrapedAt,
+		ReviewsFetched: bookData.Fetched(),
+	}
+
+	s.rememberBook(bookData)
+
+	header := make([]string, 0, len(bookFields))
+	record := make([]string, 0, len(bookFields))
+	for _, field := range bookFields {
+		header = append(header, field.Name)
+		record = append(record, field.Value(book, s.opts.timeFormat))
+	}
+
+	return s.appendCSV(outputPath, header, true, [][]string{record})
+}
+
+// appendCSV appends records to a CSV file and syncs it to disk. The header is
+// written if the file is new or empty. The first time a file is appended to,
+// its header is checked against the expected one and a partial record left by
+// an interrupted write is trimmed. Paths ending in .gz or .zst get each batch
+// as its own compressed member.
+//
+// A default header only ever gains columns at the end. If it is extensible, a
+// file whose header is a leading part of it was written by an older version;
+// its records are appended with the file's own columns.
+func (s *CSVStorage) appendCSV(outputPath string, header []string, extensible bool, records [][]string) error {
 	// Ensure directory exists
 	dir := filepath.Dir(outputPath)
 	if dir != "." {
@@ -27,54 +142,194 @@ func (s *CSVStorage) SaveReviews(reviews []models.Review, outputPath string) err
 		}
 	}
 
-	// Check if file exists to determine if we need to write header
-	fileExists := false
-	if _, err := os.Stat(outputPath); err == nil {
-		fileExists = true
-	}
-
-	file, err := os.OpenFile(outputPath, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0644)
+	file, err := os.OpenFile(outputPath, os.O_RDWR|os.O_CREATE, 0644)
 	if err != nil {
 		return fmt.Errorf("failed to open output file: %w", err)
 	}
 	defer file.Close()
 
-	writer := csv.NewWriter(file)
-	defer writer.Flush()
+	info, err := file.Stat()
+	if err != nil {
+		return fmt.Errorf("failed to stat output file: %w", err)
+	}
+	size := info.Size()
+
+	s.mu.Lock()
+	columns, checked := s.checked[outputPath]
+	s.mu.Unlock()
 
-	// Write header only if file is new
-	if !fileExists {
-		header := []string{
-			"BookURL", "BookTitle", "ReviewerName",
-			"Rating", "ReviewText", "ReviewDate", "Language",
+	_, compression := SplitCompression(outputPath)
+	if !checked {
+		columns = len(header)
+	}
+	if size > 0 && !checked {
+		var end int64
+		if compression == CompressionNone {
+			end, columns, err = recoverCSV(file, size, header, extensible, s.opts.csvDialect.Delimiter)
+		} else {
+			end, columns, err = recoverCompressedCSV(file, size, compression, header, extensible, s.opts.csvDialect.Delimiter)
+		}
+		if err != nil {
+			return fmt.Errorf("%s: %w", outputPath, err)
 		}
-		if err := writer.Write(header); err != nil {
-			return fmt.Errorf("failed to write header: %w", err)
+		if end < size {
+			if err := file.Truncate(end); err != nil {
+				return fmt.Errorf("failed to trim partial record: %w", err)
+			}
+			size = end
 		}
 	}
 
-	// Write reviews
-	for _, review := range reviews {
-		record := []string{
-			review.BookURL,
-			review.BookTitle,
-			review.ReviewerName,
-			review.Rating,
-			review.ReviewText,
-			review.ReviewDate,
-			review.Language,
-		}
-		if err := writer.Write(record); err != nil {
-			return fmt.Errorf("failed to write record: %w", err)
+	if _, err := file.Seek(size, io.SeekStart); err != nil {
+		return fmt.Errorf("failed to seek output file: %w", err)
+	}
+
+	dialect := s.opts.csvDialect
+	var buf strings.Builder
+
+	// Write header only if file is new or empty
+	if size == 0 {
+		columns = len(header)
+		if dialect.BOM {
+			buf.WriteString(utf8BOM)
 		}
+		dialect.appendRecord(&buf, header)
+	}
+	for _, record := range records {
+		dialect.appendRecord(&buf, record[:columns])
+	}
+
+	if err := writeCompressed(file, compression, buf.String()); err != nil {
+		return fmt.Errorf("failed to write output file: %w", err)
+	}
+	if err := file.Sync(); err != nil {
+		return fmt.Errorf("failed to sync output file: %w", err)
 	}
 
+	s.mu.Lock()
+	s.checked[outputPath] = columns
+	s.mu.Unlock()
 	return nil
 }
 
-// SaveBookData saves book data to a CSV file
-func (s *CSVStorage) SaveBookData(bookData models.BookData, outputPath string) error {
-	// This is a placeholder implementation
-	// You may want to implement specific logic for saving book data
-	return fmt.Errorf("SaveBookData not yet implemented")
+// recoverCSV validates an existing CSV file and returns the offset just past
+// its last complete record. A record is incomplete if it lacks its trailing
+// newline or fails to parse at the end of the file, which is what an
+// interrupted write leaves behind. An offset of 0 means not even the header
+// was complete. The file's number of columns is returned with the offset.
+// Files with a different header, or malformed records before the end, are
+// rejected rather than appended to.
+func recoverCSV(file *os.File, size int64, header []string, extensible bool, delimiter rune) (int64, int, error) {
+	last := make([]byte, 1)
+	if _, err := file.ReadAt(last, size-1); err != nil {
+		return 0, 0, fmt.Errorf("failed to read output file: %w", err)
+	}
+	endsWithNewline := last[0] == '\n'
+
+	reader := csv.NewReader(io.NewSectionReader(file, 0, size))
+	reader.Comma = delimiter
+	reader.FieldsPerRecord = -1
+
+	// complete reports whether the record just read was fully written
+	complete := func() bool {
+		return reader.InputOffset() < size || endsWithNewline
+	}
+
+	first, err := reader.Read()
+	if err != nil || !complete() {
+		if reader.InputOffset() >= size {
+			return 0, len(header), nil
+		}
+		return 0, 0, fmt.Errorf("failed to read header: %w", err)
+	}
+	if err := checkHeader(first, header, extensible); err != nil {
+		return 0, 0, err
+	}
+
+	good := reader.InputOffset()
+	for {
+		record, err := reader.Read()
+		if err == io.EOF {
+			return good, len(first), nil
+		}
+		atEnd := reader.InputOffset() >= size
+		if err == nil && len(record) != len(first) {
+			line, _ := reader.FieldPos(0)
+			err = fmt.Errorf("record on line %d has %d columns, expected %d", line, len(record), len(first))
+		}
+		if err != nil || !complete() {
+			if atEnd {
+				return good, len(first), nil
+			}
+			return 0, 0, fmt.Errorf("malformed CSV: %w", err)
+		}
+		good = reader.InputOffset()
+	}
+}
+
+// recoverCompressedCSV is recoverCSV for a compressed file: a truncated
+// trailing member is trimmed and the header of the first member is checked.
+// Complete members always hold whole records, as each is written in one go.
+func recoverCompressedCSV(file *os.File, size int64, compression Compression, header []string, extensible bool, delimiter rune) (int64, int, error) {
+	end, err := recoverCompressed(file, size, compression)
+	if err != nil || end == 0 {
+		return end, len(header), err
+	}
+
+	decompressor, err := newDecompressor(io.NewSectionReader(file, 0, end), compression)
+	if err != nil {
+		return 0, 0, fmt.Errorf("failed to read output file: %w", err)
+	}
+	defer decompressor.Close()
+
+	reader := csv.NewReader(decompressor)
+	reader.Comma = delimiter
+	reader.FieldsPerRecord = -1
+	first, err := reader.Read()
+	if err != nil {
+		return 0, 0, fmt.Errorf("failed to read header: %w", err)
+	}
+	if err := checkHeader(first, header, extensible); err != nil {
+		return 0, 0, err
+	}
+	return end, len(first), nil
+}
+
+// checkHeader checks the header read from an existing file against the
+// expected one. If the expected header is extensible, the file's header may
+// be a leading part of it.
+func checkHeader(first []string, header []string, extensible bool) error {
+	first[0] = strings.TrimPrefix(first[0], utf8BOM)
+	if slices.Equal(first, header) {
+		return nil
+	}
+	if extensible && len(first) < len(header) && slices.Equal(first, header[:len(first)]) {
+		return nil
+	}
+	return fmt.Errorf("existing file has a different header (%d columns, expected %d); use a new output file", len(first), len(header))
+}
+
+// joinTags joins tag names with "; "
+func joinTags(tags []models.ReviewTag) string {
+	names := make([]string, 0, len(tags))
+	for _, tag := range tags {
+		names = append(names, tag.Name)
+	}
+	return strings.Join(names, "; ")
+}
+
+// formatDate formats t as a date, or returns an empty string for the zero time
+func formatDate(t time.Time) string {
+	if t.IsZero() {
+		return ""
+	}
+	return t.Format("2006-01-02")
+}
+
+// formatRating formats an optional rating, returning an empty string if unrated
+func formatRating(rating *int) string {
+	if rating == nil {
+		return ""
+	}
+	return strconv.Itoa(*rating)
 }
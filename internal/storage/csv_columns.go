@@ -0,0 +1,181 @@
+package storage
+
+import (
+	"fmt"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/rizkirmdhnnn/goodreadscrape/internal/models"
+)
+
+// csvBook is the book-level data a CSV row can draw from
+type csvBook struct {
+	Metadata       models.BookMetadata
+	ScrapedAt      time.Time
+	ReviewsFetched int
+}
+
+// reviewField is a CSV column taken from a review
+type reviewField struct {
+	Name  string
+	Value func(r models.Review, f TimeFormat) string
+}
+
+// bookField is a CSV column taken from a book
+type bookField struct {
+	Name  string
+	Value func(b csvBook, f TimeFormat) string
+}
+
+// reviewFields lists every review column, in the default order. New columns go
+// at the end, so files written by older versions can still be appended to.
+var reviewFields = []reviewField{
+	{"BookURL", func(r models.Review, _ TimeFormat) string { return r.BookURL }},
+	{"BookTitle", func(r models.Review, _ TimeFormat) string { return r.BookTitle }},
+	{"ReviewerName", func(r models.Review, _ TimeFormat) string { return r.ReviewerName }},
+	{"Rating", func(r models.Review, _ TimeFormat) string { return formatRating(r.Rating) }},
+	{"ReviewText", func(r models.Review, _ TimeFormat) string { return r.ReviewText }},
+	{"ReviewDate", func(r models.Review, f TimeFormat) string { return f.Format(r.CreatedAt) }},
+	{"Language", func(r models.Review, _ TimeFormat) string { return r.Language }},
+	{"WorkID", func(r models.Review, _ TimeFormat) string { return r.WorkID }},
+	{"ReviewURL", func(r models.Review, _ TimeFormat) string { return r.ReviewURL }},
+	{"ReviewerID", func(r models.Review, _ TimeFormat) string { return r.ReviewerID }},
+	{"ReviewerURL", func(r models.Review, _ TimeFormat) string { return r.ReviewerURL }},
+	{"ReviewerIsAuthor", func(r models.Review, _ TimeFormat) string { return strconv.FormatBool(r.ReviewerIsAuthor) }},
+	{"ReviewerFollowersCount", func(r models.Review, _ TimeFormat) string { return strconv.Itoa(r.ReviewerFollowersCount) }},
+	{"ReviewerTextReviewsCount", func(r models.Review, _ TimeFormat) string { return strconv.Itoa(r.ReviewerTextReviewsCount) }},
+	{"LikeCount", func(r models.Review, _ TimeFormat) string { return strconv.Itoa(r.LikeCount) }},
+	{"CommentCount", func(r models.Review, _ TimeFormat) string { return strconv.Itoa(r.CommentCount) }},
+	{"Spoiler", func(r models.Review, _ TimeFormat) string { return strconv.FormatBool(r.Spoiler) }},
+	{"UpdatedAt", func(r models.Review, f TimeFormat) string { return f.Format(r.UpdatedAt) }},
+	{"LastRevisionAt", func(r models.Review, f TimeFormat) string { return f.Format(r.LastRevisionAt) }},
+	{"Shelf", func(r models.Review, _ TimeFormat) string { return r.Shelf }},
+	{"Tags", func(r models.Review, _ TimeFormat) string { return joinTags(r.Tags) }},
+	{"ReviewID", func(r models.Review, _ TimeFormat) string { return r.ReviewID }},
+	{"LanguageConfidence", func(r models.Review, _ TimeFormat) string {
+		return strconv.FormatFloat(r.LanguageConfidence, 'f', 3, 64)
+	}},
+	{"FetchedLanguage", func(r models.Review, _ TimeFormat) string { return r.FetchedLanguage }},
+}
+
+// bookFields lists every book column, in the order of the books file
+var bookFields = []bookField{
+	{"WorkID", func(b csvBook, _ TimeFormat) string { return b.Metadata.WorkID }},
+	{"BookID", func(b csvBook, _ TimeFormat) string { return b.Metadata.BookID }},
+	{"BookURL", func(b csvBook, _ TimeFormat) string { return b.Metadata.URL }},
+	{"Title", func(b csvBook, _ TimeFormat) string { return b.Metadata.Title }},
+	{"Author", func(b csvBook, _ TimeFormat) string { return b.Metadata.Author }},
+	{"Contributors", func(b csvBook, _ TimeFormat) string { return joinContributors(b.Metadata.Contributors) }},
+	{"AverageRating", func(b csvBook, _ TimeFormat) string {
+		return strconv.FormatFloat(b.Metadata.AverageRating, 'f', -1, 64)
+	}},
+	{"RatingsCount", func(b csvBook, _ TimeFormat) string { return strconv.Itoa(b.Metadata.RatingsCount) }},
+	{"TextReviewsCount", func(b csvBook, _ TimeFormat) string { return strconv.Itoa(b.Metadata.TextReviewsCount) }},
+	{"Ratings1", func(b csvBook, _ TimeFormat) string { return strconv.Itoa(b.Metadata.RatingDistribution[0]) }},
+	{"Ratings2", func(b csvBook, _ TimeFormat) string { return strconv.Itoa(b.Metadata.RatingDistribution[1]) }},
+	{"Ratings3", func(b csvBook, _ TimeFormat) string { return strconv.Itoa(b.Metadata.RatingDistribution[2]) }},
+	{"Ratings4", func(b csvBook, _ TimeFormat) string { return strconv.Itoa(b.Metadata.RatingDistribution[3]) }},
+	{"Ratings5", func(b csvBook, _ TimeFormat) string { return strconv.Itoa(b.Metadata.RatingDistribution[4]) }},
+	{"ISBN10", func(b csvBook, _ TimeFormat) string { return b.Metadata.ISBN10 }},
+	{"ISBN13", func(b csvBook, _ TimeFormat) string { return b.Metadata.ISBN13 }},
+	{"ASIN", func(b csvBook, _ TimeFormat) string { return b.Metadata.ASIN }},
+	{"Language", func(b csvBook, _ TimeFormat) string { return b.Metadata.Language }},
+	{"PageCount", func(b csvBook, _ TimeFormat) string { return strconv.Itoa(b.Metadata.PageCount) }},
+	{"Format", func(b csvBook, _ TimeFormat) string { return b.Metadata.Format }},
+	{"Publisher", func(b csvBook, _ TimeFormat) string { return b.Metadata.Publisher }},
+	{"EditionDate", func(b csvBook, _ TimeFormat) string { return formatDate(b.Metadata.EditionDate) }},
+	{"FirstPublished", func(b csvBook, _ TimeFormat) string { return formatDate(b.Metadata.FirstPublished) }},
+	{"Genres", func(b csvBook, _ TimeFormat) string { return strings.Join(b.Metadata.Genres, "; ") }},
+	{"SeriesName", func(b csvBook, _ TimeFormat) string { return b.Metadata.SeriesName }},
+	{"SeriesPosition", func(b csvBook, _ TimeFormat) string { return b.Metadata.SeriesPosition }},
+	{"CoverURL", func(b csvBook, _ TimeFormat) string { return b.Metadata.CoverURL }},
+	{"Description", func(b csvBook, _ TimeFormat) string { return b.Metadata.Description }},
+	{"ScrapedAt", func(b csvBook, f TimeFormat) string { return f.Format(b.ScrapedAt) }},
+	{"ReviewsFetched", func(b csvBook, _ TimeFormat) string { return strconv.Itoa(b.ReviewsFetched) }},
+}
+
+// bookLanguageColumn names the book's language in the reviews file, where
+// Language is the review's own language
+const bookLanguageColumn = "BookLanguage"
+
+// reviewColumn resolves a reviews file column to its value for one review
+// and the review's book
+type reviewColumn func(r models.Review, b csvBook, f TimeFormat) string
+
+// DefaultReviewColumns returns the default columns of the reviews file
+func DefaultReviewColumns() []string {
+	names := make([]string, 0, len(reviewFields))
+	for _, field := range reviewFields {
+		names = append(names, field.Name)
+	}
+	return names
+}
+
+// ReviewColumnNames returns every column the reviews file can hold: all
+// review fields followed by the book fields not already covered
+func ReviewColumnNames() []string {
+	names := DefaultReviewColumns()
+	for _, field := range bookFields {
+		name := field.Name
+		if name == "Language" {
+			name = bookLanguageColumn
+		}
+		if _, ok := lookupReviewColumn(name); ok && !slices.Contains(names, name) {
+			names = append(names, name)
+		}
+	}
+	return names
+}
+
+// ValidateReviewColumns checks that every name is a known reviews file column
+// and that none is repeated
+func ValidateReviewColumns(names []string) error {
+	seen := make(map[string]bool)
+	for _, name := range names {
+		if _, ok := lookupReviewColumn(name); !ok {
+			return fmt.Errorf("unknown CSV column %q (available: %s)", name, strings.Join(ReviewColumnNames(), ", "))
+		}
+		if seen[name] {
+			return fmt.Errorf("CSV column %q is listed more than once", name)
+		}
+		seen[name] = true
+	}
+	return nil
+}
+
+// lookupReviewColumn resolves a reviews file column name. Review fields take
+// precedence over book fields of the same name.
+func lookupReviewColumn(name string) (reviewColumn, bool) {
+	for _, field := range reviewFields {
+		if field.Name == name {
+			value := field.Value
+			return func(r models.Review, _ csvBook, f TimeFormat) string { return value(r, f) }, true
+		}
+	}
+
+	if name == bookLanguageColumn {
+		name = "Language"
+	}
+	for _, field := range bookFields {
+		if field.Name == name {
+			value := field.Value
+			return func(_ models.Review, b csvBook, f TimeFormat) string { return value(b, f) }, true
+		}
+	}
+	return nil, false
+}
+
+// joinContributors formats contributors as "Name (Role); ..."
+func joinContributors(contributors []models.Contributor) string {
+	names := make([]string, 0, len(contributors))
+	for _, c := range contributors {
+		if c.Role != "" {
+			names = append(names, fmt.Sprintf("%s (%s)", c.Name, c.Role))
+		} else {
+			names = append(names, c.Name)
+		}
+	}
+	return strings.Join(names, "; ")
+}
@@ -0,0 +1,163 @@
+package storage
+
+import (
+	"fmt"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+// utf8BOM marks a file as UTF-8 for Excel
+const utf8BOM = "\ufeff"
+
+// NewlineMode controls how line breaks inside fields, such as multi-paragraph
+// review text, are written
+type NewlineMode string
+
+// Supported newline modes
+const (
+	NewlinesKeep   NewlineMode = "keep"   // Keep line breaks inside quoted fields
+	NewlinesSpace  NewlineMode = "space"  // Replace line breaks with a space
+	NewlinesEscape NewlineMode = "escape" // Replace line breaks with a literal \n
+)
+
+// CSVDialect describes how CSV output is encoded
+type CSVDialect struct {
+	Delimiter rune
+	BOM       bool // Write a UTF-8 byte order mark at the start of new files
+	CRLF      bool // Terminate lines with \r\n instead of \n
+	QuoteAll  bool // Quote every field instead of only those that need it
+	Newlines  NewlineMode
+}
+
+// DefaultCSVDialect is RFC 4180 style CSV with \n line endings
+var DefaultCSVDialect = CSVDialect{Delimiter: ',', Newlines: NewlinesKeep}
+
+// ParseCSVDialect builds a dialect from its flag values. The delimiter is
+// comma, tab, semicolon or a single character; quoting is minimal or all.
+func ParseCSVDialect(delimiter string, quoting string, newlines string, bom bool, crlf bool) (CSVDialect, error) {
+	dialect := DefaultCSVDialect
+	dialect.BOM = bom
+	dialect.CRLF = crlf
+
+	switch delimiter {
+	case "", "comma":
+		dialect.Delimiter = ','
+	case "tab", `\t`:
+		dialect.Delimiter = '\t'
+	case "semicolon":
+		dialect.Delimiter = ';'
+	default:
+		r, size := utf8.DecodeRuneInString(delimiter)
+		if size != len(delimiter) || r == '"' || r == '\r' || r == '\n' || r == utf8.RuneError {
+			return CSVDialect{}, fmt.Errorf("invalid CSV delimiter %q (use comma, tab, semicolon or a single character)", delimiter)
+		}
+		dialect.Delimiter = r
+	}
+
+	switch quoting {
+	case "", "minimal":
+		dialect.QuoteAll = false
+	case "all":
+		dialect.QuoteAll = true
+	default:
+		return CSVDialect{}, fmt.Errorf("unknown CSV quoting %q (use minimal or all)", quoting)
+	}
+
+	switch mode := NewlineMode(newlines); mode {
+	case "":
+		dialect.Newlines = NewlinesKeep
+	case NewlinesKeep, NewlinesSpace, NewlinesEscape:
+		dialect.Newlines = mode
+	default:
+		return CSVDialect{}, fmt.Errorf("unknown newline mode %q (use %s, %s or %s)", newlines, NewlinesKeep, NewlinesSpace, NewlinesEscape)
+	}
+
+	return dialect, nil
+}
+
+// WithCSVDialect sets how CSV output is encoded
+func WithCSVDialect(dialect CSVDialect) Option {
+	return func(o *options) {
+		if dialect.Delimiter != 0 {
+			o.csvDialect = dialect
+		}
+	}
+}
+
+// WithCSVColumns sets the ordered columns of the CSV reviews file. Names are
+// validated by ValidateReviewColumns; an empty list keeps the defaults.
+func WithCSVColumns(columns []string) Option {
+	return func(o *options) {
+		if len(columns) > 0 {
+			o.csvColumns = columns
+		}
+	}
+}
+
+// Replacers for NewlinesSpace and NewlinesEscape
+var (
+	newlineToSpace  = strings.NewReplacer("\r\n", " ", "\r", " ", "\n", " ")
+	newlineToEscape = strings.NewReplacer("\r\n", `\n`, "\r", `\n`, "\n", `\n`)
+)
+
+// lineEnd returns the line terminator
+func (d CSVDialect) lineEnd() string {
+	if d.CRLF {
+		return "\r\n"
+	}
+	return "\n"
+}
+
+// appendRecord encodes fields as one line and appends it to buf
+func (d CSVDialect) appendRecord(buf *strings.Builder, fields []string) {
+	for i, field := range fields {
+		if i > 0 {
+			buf.WriteRune(d.Delimiter)
+		}
+
+		switch d.Newlines {
+		case NewlinesSpace:
+			field = newlineToSpace.Replace(field)
+		case NewlinesEscape:
+			field = newlineToEscape.Replace(field)
+		}
+
+		if !d.QuoteAll && !d.needsQuotes(field) {
+			buf.WriteString(field)
+			continue
+		}
+
+		buf.WriteByte('"')
+		for _, r := range field {
+			switch {
+			case r == '"':
+				buf.WriteString(`""`)
+			case r == '\n' && d.CRLF:
+				buf.WriteString("\r\n")
+			case r == '\r' && d.CRLF:
+				// Dropped; \r\n pairs are written from the \n
+			default:
+				buf.WriteRune(r)
+			}
+		}
+		buf.WriteByte('"')
+	}
+	buf.WriteString(d.lineEnd())
+}
+
+// needsQuotes reports whether field must be quoted to round-trip, following
+// the same rules as encoding/csv
+func (d CSVDialect) needsQuotes(field string) bool {
+	if field == "" {
+		return false
+	}
+	if field == `\.` {
+		return true
+	}
+	if strings.ContainsRune(field, d.Delimiter) || strings.ContainsAny(field, "\"\r\n") {
+		return true
+	}
+	r, _ := utf8.DecodeRuneInString(field)
+	return unicode.IsSpace(r)
+}
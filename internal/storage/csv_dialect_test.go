@@ -0,0 +1,141 @@
+package storage
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/rizkirmdhnnn/goodreadscrape/internal/models"
+)
+
+func TestParseCSVDialect(t *testing.T) {
+	tests := []struct {
+		name      string
+		delimiter string
+		quoting   string
+		newlines  string
+		expected  rune
+		wantErr   bool
+	}{
+		{"Defaults", "", "", "", ',', false},
+		{"Tab", "tab", "all", "space", '\t', false},
+		{"Semicolon", "semicolon", "minimal", "escape", ';', false},
+		{"Single character", "|", "", "", '|', false},
+		{"Multi-character delimiter", "||", "", "", 0, true},
+		{"Quote as delimiter", `"`, "", "", 0, true},
+		{"Unknown quoting", "", "some", "", 0, true},
+		{"Unknown newline mode", "", "", "strip", 0, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dialect, err := ParseCSVDialect(tt.delimiter, tt.quoting, tt.newlines, false, false)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("ParseCSVDialect() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if dialect.Delimiter != tt.expected {
+				t.Errorf("Expected delimiter %q, got %q", tt.expected, dialect.Delimiter)
+			}
+		})
+	}
+}
+
+func TestCSVDialect_AppendRecord(t *testing.T) {
+	fields := []string{"plain", "a;b", `say "hi"`, "line one\nline two", ""}
+
+	tests := []struct {
+		name     string
+		dialect  CSVDialect
+		expected string
+	}{
+		{"Default", DefaultCSVDialect, "plain,a;b,\"say \"\"hi\"\"\",\"line one\nline two\",\n"},
+		{"Semicolon", CSVDialect{Delimiter: ';'}, "plain;\"a;b\";\"say \"\"hi\"\"\";\"line one\nline two\";\n"},
+		{"Quote all", CSVDialect{Delimiter: ',', QuoteAll: true}, "\"plain\",\"a;b\",\"say \"\"hi\"\"\",\"line one\nline two\",\"\"\n"},
+		{"CRLF", CSVDialect{Delimiter: ',', CRLF: true}, "plain,a;b,\"say \"\"hi\"\"\",\"line one\r\nline two\",\r\n"},
+		{"Newlines as space", CSVDialect{Delimiter: ',', Newlines: NewlinesSpace}, "plain,a;b,\"say \"\"hi\"\"\",line one line two,\n"},
+		{"Newlines escaped", CSVDialect{Delimiter: ',', Newlines: NewlinesEscape}, "plain,a;b,\"say \"\"hi\"\"\",line one\\nline two,\n"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var buf strings.Builder
+			tt.dialect.appendRecord(&buf, fields)
+			if buf.String() != tt.expected {
+				t.Errorf("Expected %q, got %q", tt.expected, buf.String())
+			}
+		})
+	}
+}
+
+func TestCSVStorage_DialectAndColumns(t *testing.T) {
+	tmpPath := filepath.Join(t.TempDir(), "reviews.csv")
+
+	dialect, err := ParseCSVDialect("semicolon", "minimal", "keep", true, false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	s := NewCSVStorage(
+		WithCSVDialect(dialect),
+		WithCSVColumns([]string{"ReviewID", "Title", "Author", "BookLanguage", "Language", "Rating"}),
+	)
+
+	// Book columns come from the book saved before its reviews, as app.Run does
+	book := models.BookData{Metadata: models.BookMetadata{
+		WorkID:   "kca://work/1",
+		Title:    "Laskar Pelangi",
+		Author:   "Andrea Hirata",
+		Language: "Indonesian",
+	}}
+	if err := s.SaveBookData(book, filepath.Join(t.TempDir(), "books.csv")); err != nil {
+		t.Fatalf("SaveBookData failed: %v", err)
+	}
+
+	five := 5
+	reviews := []models.Review{{ReviewID: "r1", WorkID: "kca://work/1", Language: "id", Rating: &five}}
+	// Two batches from separate storages, so the second validates the BOM header on disk
+	if err := s.SaveReviews(reviews, tmpPath); err != nil {
+		t.Fatalf("SaveReviews failed: %v", err)
+	}
+	second := NewCSVStorage(
+		WithCSVDialect(dialect),
+		WithCSVColumns([]string{"ReviewID", "Title", "Author", "BookLanguage", "Language", "Rating"}),
+	)
+	if err := second.SaveReviews([]models.Review{{ReviewID: "r2", WorkID: "kca://work/2"}}, tmpPath); err != nil {
+		t.Fatalf("SaveReviews failed: %v", err)
+	}
+
+	content, err := os.ReadFile(tmpPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	expected := utf8BOM +
+		"ReviewID;Title;Author;BookLanguage;Language;Rating\n" +
+		"r1;Laskar Pelangi;Andrea Hirata;Indonesian;id;5\n" +
+		"r2;;;;;\n"
+	if string(content) != expected {
+		t.Errorf("Expected %q, got %q", expected, content)
+	}
+
+	// Chosen columns are not extended like the default ones, so an added column is a different header
+	third := NewCSVStorage(
+		WithCSVDialect(dialect),
+		WithCSVColumns([]string{"ReviewID", "Title", "Author", "BookLanguage", "Language", "Rating", "Shelf"}),
+	)
+	if err := third.SaveReviews([]models.Review{{ReviewID: "r3"}}, tmpPath); err == nil {
+		t.Error("Expected error for an added column")
+	}
+}
+
+func TestValidateReviewColumns(t *testing.T) {
+	if err := ValidateReviewColumns([]string{"ReviewID", "Author", "BookLanguage", "Ratings5"}); err != nil {
+		t.Errorf("ValidateReviewColumns failed: %v", err)
+	}
+	if err := ValidateReviewColumns([]string{"Nope"}); err == nil {
+		t.Error("Expected error for unknown column")
+	}
+	if err := ValidateReviewColumns([]string{"ReviewID", "ReviewID"}); err == nil {
+		t.Error("Expected error for repeated column")
+	}
+}
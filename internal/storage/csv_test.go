@@ -3,7 +3,11 @@ package storage
 import (
 	"encoding/csv"
 	"os"
+	"path/filepath"
+	"slices"
+	"strings"
 	"testing"
+	"time"
 
 	"github.com/rizkirmdhnnn/goodreadscrape/internal/models"
 )
@@ -29,24 +33,25 @@ func TestCSVStorage_SaveReviews(t *testing.T) {
 	os.Remove(tmpPath)       // Remove the file so SaveReviews treats it as new and writes header
 	defer os.Remove(tmpPath) // clean up
 
-	s := NewCSVStorage()
+	s := NewCSVStorage(WithTimeFormat(TimeFormatDate))
+	five, four := 5, 4
 	reviews := []models.Review{
 		{
 			BookURL:      "http://example.com/book1",
 			BookTitle:    "Test Book 1",
 			ReviewerName: "John Doe",
-			Rating:       "5",
+			Rating:       &five,
 			ReviewText:   "Great book!",
-			ReviewDate:   "2023-01-01",
+			CreatedAt:    time.Date(2023, 1, 1, 12, 30, 0, 0, time.UTC),
 			Language:     "en",
 		},
 		{
 			BookURL:      "http://example.com/book2",
 			BookTitle:    "Test Book 2",
 			ReviewerName: "Jane Doe",
-			Rating:       "4",
+			Rating:       &four,
 			ReviewText:   "Good book.",
-			ReviewDate:   "2023-01-02",
+			CreatedAt:    time.Date(2023, 1, 2, 8, 0, 0, 0, time.UTC),
 			Language:     "fr",
 		},
 	}
@@ -99,14 +104,14 @@ func TestCSVStorage_SaveReviews(t *testing.T) {
 	if records[1][2] != reviews[0].ReviewerName {
 		t.Errorf("Expected ReviewerName %s, got %s", reviews[0].ReviewerName, records[1][2])
 	}
-	if records[1][3] != reviews[0].Rating {
-		t.Errorf("Expected Rating %s, got %s", reviews[0].Rating, records[1][3])
+	if records[1][3] != "5" {
+		t.Errorf("Expected Rating 5, got %s", records[1][3])
 	}
 	if records[1][4] != reviews[0].ReviewText {
 		t.Errorf("Expected ReviewText %s, got %s", reviews[0].ReviewText, records[1][4])
 	}
-	if records[1][5] != reviews[0].ReviewDate {
-		t.Errorf("Expected ReviewDate %s, got %s", reviews[0].ReviewDate, records[1][5])
+	if records[1][5] != "2023-01-01" {
+		t.Errorf("Expected ReviewDate 2023-01-01, got %s", records[1][5])
 	}
 	if records[1][6] != reviews[0].Language {
 		t.Errorf("Expected Language %s, got %s", reviews[0].Language, records[1][6])
@@ -122,14 +127,14 @@ func TestCSVStorage_SaveReviews(t *testing.T) {
 	if records[2][2] != reviews[1].ReviewerName {
 		t.Errorf("Expected ReviewerName %s, got %s", reviews[1].ReviewerName, records[2][2])
 	}
-	if records[2][3] != reviews[1].Rating {
-		t.Errorf("Expected Rating %s, got %s", reviews[1].Rating, records[2][3])
+	if records[2][3] != "4" {
+		t.Errorf("Expected Rating 4, got %s", records[2][3])
 	}
 	if records[2][4] != reviews[1].ReviewText {
 		t.Errorf("Expected ReviewText %s, got %s", reviews[1].ReviewText, records[2][4])
 	}
-	if records[2][5] != reviews[1].ReviewDate {
-		t.Errorf("Expected ReviewDate %s, got %s", reviews[1].ReviewDate, records[2][5])
+	if records[2][5] != "2023-01-02" {
+		t.Errorf("Expected ReviewDate 2023-01-02, got %s", records[2][5])
 	}
 	if records[2][6] != reviews[1].Language {
 		t.Errorf("Expected Language %s, got %s", reviews[1].Language, records[2][6])
@@ -143,12 +148,153 @@ func TestCSVStorage_SaveReviews(t *testing.T) {
 }
 
 func TestCSVStorage_SaveBookData(t *testing.T) {
+	tmpPath := filepath.Join(t.TempDir(), "books.csv")
+
 	s := NewCSVStorage()
-	err := s.SaveBookData(models.BookData{}, "dummy.csv")
-	if err == nil {
-		t.Error("Expected error from SaveBookData, got nil")
+	bookData := models.BookData{
+		Metadata: models.BookMetadata{
+			WorkID:        "kca://work/test",
+			BookID:        "123",
+			Title:         "Test Book",
+			Author:        "Test Author",
+			Contributors:  []models.Contributor{{Name: "Test Author", Role: "Author"}, {Name: "Someone", Role: "Translator"}},
+			AverageRating: 4.25,
+			RatingsCount:  10,
+			URL:           "http://example.com/book/show/123",
+			EditionDate:   time.Date(2008, 1, 1, 0, 0, 0, 0, time.UTC),
+			Genres:        []string{"Fiction", "Classics"},
+		},
+		Reviews:        []models.Review{{ReviewID: "1"}, {ReviewID: "2"}},
+		ReviewsFetched: 5, // Client-side filters kept 2 of them
+		ScrapedAt:      time.Date(2024, 5, 6, 7, 8, 9, 0, time.UTC),
+	}
+
+	// Save twice to check the header is written once
+	for i := 0; i < 2; i++ {
+		if err := s.SaveBookData(bookData, tmpPath); err != nil {
+			t.Fatalf("SaveBookData failed: %v", err)
+		}
+	}
+
+	file, err := os.Open(tmpPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer file.Close()
+
+	records, err := csv.NewReader(file).ReadAll()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(records) != 3 {
+		t.Fatalf("Expected header + 2 rows, got %d records", len(records))
+	}
+
+	row := make(map[string]string)
+	for i, column := range records[0] {
+		row[column] = records[1][i]
 	}
-	if err.Error() != "SaveBookData not yet implemented" {
-		t.Errorf("Expected 'SaveBookData not yet implemented', got '%v'", err)
+
+	expected := map[string]string{
+		"WorkID":         "kca://work/test",
+		"BookID":         "123",
+		"BookURL":        "http://example.com/book/show/123",
+		"Title":          "Test Book",
+		"Contributors":   "Test Author (Author); Someone (Translator)",
+		"AverageRating":  "4.25",
+		"RatingsCount":   "10",
+		"EditionDate":    "2008-01-01",
+		"FirstPublished": "",
+		"Genres":         "Fiction; Classics",
+		"ScrapedAt":      "2024-05-06T07:08:09Z",
+		"ReviewsFetched": "5",
+	}
+	for column, want := range expected {
+		if row[column] != want {
+			t.Errorf("Column %s: expected %q, got %q", column, want, row[column])
+		}
+	}
+}
+
+func TestBooksPath(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected string
+	}{
+		{"results/reviews.csv", "results/reviews_books.csv"},
+		{"reviews", "reviews_books"},
+		{"results/reviews.csv.gz", "results/reviews_books.csv.gz"},
+		{"results/reviews.jsonl.ZST", "results/reviews_books.jsonl.ZST"},
+	}
+
+	for _, tt := range tests {
+		if got := BooksPath(tt.input); got != tt.expected {
+			t.Errorf("BooksPath(%q) = %q, want %q", tt.input, got, tt.expected)
+		}
+	}
+}
+
+func TestCSVStorage_Recovery(t *testing.T) {
+	header := strings.Join(DefaultReviewColumns(), ",") + "\n"
+	row := "http://example.com/book1,Book,John,5,Great,2023-01-01,en,w1,,,,false,0,0,0,0,false,,,,,r1,0.990,en\n"
+
+	// Files written before the language columns were added end with ReviewID
+	columns := DefaultReviewColumns()
+	legacyHeader := strings.Join(columns[:slices.Index(columns, "ReviewID")+1], ",") + "\n"
+	legacyRow := "http://example.com/book1,Book,John,5,Great,2023-01-01,en,w1,,,,false,0,0,0,0,false,,,,,r1\n"
+
+	tests := []struct {
+		name     string
+		existing string
+		wantRows int // Rows after appending one review, excluding the header
+		wantErr  bool
+	}{
+		{"Empty file gets a header", "", 1, false},
+		{"Partial header is rewritten", "BookURL,BookTi", 1, false},
+		{"Complete file is appended to", header + row, 2, false},
+		{"Unterminated row is trimmed", header + row + "http://example.com/book2,Bo", 2, false},
+		{"Unclosed quoted field is trimmed", header + row + "http://example.com/book2,Book,Jane,4,\"Line one\nline tw", 2, false},
+		{"Short trailing row is trimmed", header + row + "http://example.com/book2,Book\n", 2, false},
+		{"File without the language columns is appended to", legacyHeader + legacyRow, 2, false},
+		{"Foreign header is rejected", "id,text\n1,hello\n", 0, true},
+		{"Longer header is rejected", strings.TrimSuffix(header, "\n") + ",Extra\n", 0, true},
+		{"Malformed row before the end is rejected", header + "a,b\n" + row, 0, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tmpPath := filepath.Join(t.TempDir(), "reviews.csv")
+			if err := os.WriteFile(tmpPath, []byte(tt.existing), 0644); err != nil {
+				t.Fatal(err)
+			}
+
+			s := NewCSVStorage()
+			err := s.SaveReviews([]models.Review{{BookURL: "http://example.com/book3", ReviewText: "New"}}, tmpPath)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("SaveReviews() error = %v, wantErr %v", err, tt.wantErr)
+			}
+
+			content, err := os.ReadFile(tmpPath)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if tt.wantErr {
+				if string(content) != tt.existing {
+					t.Errorf("Rejected file was modified: %q", content)
+				}
+				return
+			}
+
+			records, err := csv.NewReader(strings.NewReader(string(content))).ReadAll()
+			if err != nil {
+				t.Fatalf("Output is not valid CSV: %v", err)
+			}
+			if len(records) != tt.wantRows+1 {
+				t.Fatalf("Expected header + %d rows, got %d records", tt.wantRows, len(records))
+			}
+			if records[len(records)-1][0] != "http://example.com/book3" {
+				t.Errorf("Expected the new review last, got %v", records[len(records)-1])
+			}
+		})
 	}
 }
@@ -0,0 +1,105 @@
+package storage
+
+import (
+	"fmt"
+	"path/filepath"
+	"strings"
+)
+
+// Format names an output backend
+type Format string
+
+// Supported output formats
+const (
+	FormatCSV     Format = "csv"
+	FormatJSONL   Format = "jsonl"
+	FormatSQLite  Format = "sqlite"
+	FormatParquet Format = "parquet"
+)
+
+// ParseFormat validates an output format name
+func ParseFormat(name string) (Format, error) {
+	switch f := Format(strings.ToLower(name)); f {
+	case FormatCSV, FormatJSONL, FormatSQLite, FormatParquet:
+		return f, nil
+	case "ndjson":
+		return FormatJSONL, nil
+	default:
+		return "", fmt.Errorf("unknown output format %q (use %s, %s, %s or %s)", name, FormatCSV, FormatJSONL, FormatSQLite, FormatParquet)
+	}
+}
+
+// Extension returns the file extension, including the dot, used for the format
+func (f Format) Extension() string {
+	if f == FormatSQLite {
+		return ".db"
+	}
+	return "." + string(f)
+}
+
+// BooksPath returns where the format stores book metadata for a reviews
+// output path. SQLite keeps books in the same database; file formats use a
+// companion file (see BooksPath).
+func (f Format) BooksPath(reviewsPath string) string {
+	if f == FormatSQLite {
+		return reviewsPath
+	}
+	return BooksPath(reviewsPath)
+}
+
+// Compressible reports whether the format is a stream that can be written
+// through gzip or zstd. SQLite needs random access and Parquet compresses
+// internally (see ParquetCodec).
+func (f Format) Compressible() bool {
+	return f == FormatCSV || f == FormatJSONL
+}
+
+// ResolveFormat returns the explicit format name if set, otherwise the format
+// inferred from outputPath's extension, defaulting to CSV. A compression
+// extension (.gz, .zst) is looked through, and is an error for formats that
+// cannot be compressed.
+func ResolveFormat(name string, outputPath string) (Format, error) {
+	path, compression := SplitCompression(outputPath)
+	format, err := inferFormat(name, path)
+	if err != nil {
+		return "", err
+	}
+	if compression != CompressionNone && !format.Compressible() {
+		return "", fmt.Errorf("%s output cannot be %s compressed", format, compression)
+	}
+	return format, nil
+}
+
+// inferFormat returns the explicit format name if set, otherwise the format
+// of path's extension
+func inferFormat(name string, path string) (Format, error) {
+	if name != "" {
+		return ParseFormat(name)
+	}
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".jsonl", ".ndjson":
+		return FormatJSONL, nil
+	case ".db", ".sqlite", ".sqlite3":
+		return FormatSQLite, nil
+	case ".parquet":
+		return FormatParquet, nil
+	default:
+		return FormatCSV, nil
+	}
+}
+
+// New creates the storage backend for format
+func New(format Format, opts ...Option) (Storage, error) {
+	switch format {
+	case FormatCSV:
+		return NewCSVStorage(opts...), nil
+	case FormatJSONL:
+		return NewJSONLStorage(opts...), nil
+	case FormatSQLite:
+		return NewSQLiteStorage(opts...), nil
+	case FormatParquet:
+		return NewParquetStorage(opts...), nil
+	default:
+		return nil, fmt.Errorf("unknown output format %q", format)
+	}
+}
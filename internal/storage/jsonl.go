@@ -0,0 +1,310 @@
+package storage
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+
+	"github.com/rizkirmdhnnn/goodreadscrape/internal/models"
+)
+
+// JSONLStorage implements Storage interface for JSON Lines output
+type JSONLStorage struct {
+	opts options
+
+	mu      sync.Mutex
+	checked map[string]bool // Files whose tail was validated
+}
+
+// NewJSONLStorage creates a new JSON Lines storage instance
+func NewJSONLStorage(opts ...Option) Storage {
+	return &JSONLStorage{
+		opts:    newOptions(opts),
+		checked: make(map[string]bool),
+	}
+}
+
+// jsonTag is the JSON form of models.ReviewTag
+type jsonTag struct {
+	Name string `json:"name"`
+	URL  string `json:"url,omitempty"`
+}
+
+// jsonReview is the JSON form of models.Review
+type jsonReview struct {
+	WorkID       string `json:"work_id"`
+	BookURL      string `json:"book_url"`
+	BookTitle    string `json:"book_title"`
+	ReviewID     string `json:"review_id"`
+	ReviewURL    string `json:"review_url,omitempty"`
+	ReviewerName string `json:"reviewer_name"`
+	Rating       *int   `json:"rating"`
+	ReviewText   string `json:"review_text"`
+
+	Language           string  `json:"language,omitempty"`
+	LanguageConfidence float64 `json:"language_confidence,omitempty"`
+	FetchedLanguage    string  `json:"fetched_language,omitempty"`
+
+	CreatedAt      any `json:"created_at"`
+	UpdatedAt      any `json:"updated_at"`
+	LastRevisionAt any `json:"last_revision_at"`
+
+	ReviewerID               string `json:"reviewer_id,omitempty"`
+	ReviewerURL              string `json:"reviewer_url,omitempty"`
+	ReviewerIsAuthor         bool   `json:"reviewer_is_author"`
+	ReviewerFollowersCount   int    `json:"reviewer_followers_count"`
+	ReviewerTextReviewsCount int    `json:"reviewer_text_reviews_count"`
+
+	LikeCount    int       `json:"like_count"`
+	CommentCount int       `json:"comment_count"`
+	Spoiler      bool      `json:"spoiler"`
+	Shelf        string    `json:"shelf,omitempty"`
+	Tags         []jsonTag `json:"tags"`
+}
+
+// jsonContributor is the JSON form of models.Contributor
+type jsonContributor struct {
+	ID   string `json:"id,omitempty"`
+	Name string `json:"name"`
+	Role string `json:"role,omitempty"`
+	URL  string `json:"url,omitempty"`
+}
+
+// jsonBookMetadata is the JSON form of a book without its reviews
+type jsonBookMetadata struct {
+	WorkID             string            `json:"work_id"`
+	BookID             string            `json:"book_id"`
+	BookURL            string            `json:"book_url"`
+	Title              string            `json:"title"`
+	Author             string            `json:"author"`
+	Contributors       []jsonContributor `json:"contributors"`
+	AverageRating      float64           `json:"average_rating"`
+	RatingsCount       int               `json:"ratings_count"`
+	TextReviewsCount   int               `json:"text_reviews_count"`
+	RatingDistribution [5]int            `json:"rating_distribution"`
+
+	ISBN10         string `json:"isbn10,omitempty"`
+	ISBN13         string `json:"isbn13,omitempty"`
+	ASIN           string `json:"asin,omitempty"`
+	Language       string `json:"language,omitempty"`
+	PageCount      int    `json:"page_count,omitempty"`
+	Format         string `json:"format,omitempty"`
+	Publisher      string `json:"publisher,omitempty"`
+	EditionDate    string `json:"edition_date,omitempty"`
+	FirstPublished string `json:"first_published,omitempty"`
+
+	Genres         []string `json:"genres"`
+	SeriesName     string   `json:"series_name,omitempty"`
+	SeriesPosition string   `json:"series_position,omitempty"`
+	CoverURL       string   `json:"cover_url,omitempty"`
+	Description    string   `json:"description,omitempty"`
+
+	ScrapedAt      any `json:"scraped_at"`
+	ReviewsFetched int `json:"reviews_fetched"`
+}
+
+// jsonBook is the JSON form of models.BookData, embedding metadata and reviews
+type jsonBook struct {
+	jsonBookMetadata
+	Reviews []jsonReview `json:"reviews"`
+}
+
+// SaveReviews appends one JSON object per review to outputPath
+func (s *JSONLStorage) SaveReviews(reviews []models.Review, outputPath string) error {
+	values := make([]any, 0, len(reviews))
+	for _, review := range reviews {
+		values = append(values, s.toJSONReview(review))
+	}
+	return s.appendJSONL(outputPath, values)
+}
+
+// RememberBook does nothing: JSON Lines reviews carry no book fields, and the
+// book's object is already in the books file
+func (s *JSONLStorage) RememberBook(bookData models.BookData, outputPath string) {}
+
+// SaveBookData appends one JSON object holding the book's metadata and all its reviews
+func (s *JSONLStorage) SaveBookData(bookData models.BookData, outputPath string) error {
+	book := jsonBook{
+		jsonBookMetadata: s.toJSONBookMetadata(bookData),
+		Reviews:          make([]jsonReview, 0, len(bookData.Reviews)),
+	}
+	for _, review := range bookData.Reviews {
+		book.Reviews = append(book.Reviews, s.toJSONReview(review))
+	}
+
+	return s.appendJSONL(outputPath, []any{book})
+}
+
+// toJSONBookMetadata converts a book's metadata to its JSON form
+func (s *JSONLStorage) toJSONBookMetadata(bookData models.BookData) jsonBookMetadata {
+	m := bookData.Metadata
+
+	book := jsonBookMetadata{
+		WorkID:             m.WorkID,
+		BookID:             m.BookID,
+		BookURL:            m.URL,
+		Title:              m.Title,
+		Author:             m.Author,
+		Contributors:       make([]jsonContributor, 0, len(m.Contributors)),
+		AverageRating:      m.AverageRating,
+		RatingsCount:       m.RatingsCount,
+		TextReviewsCount:   m.TextReviewsCount,
+		RatingDistribution: m.RatingDistribution,
+		ISBN10:             m.ISBN10,
+		ISBN13:             m.ISBN13,
+		ASIN:               m.ASIN,
+		Language:           m.Language,
+		PageCount:          m.PageCount,
+		Format:             m.Format,
+		Publisher:          m.Publisher,
+		EditionDate:        formatDate(m.EditionDate),
+		FirstPublished:     formatDate(m.FirstPublished),
+		Genres:             m.Genres,
+		SeriesName:         m.SeriesName,
+		SeriesPosition:     m.SeriesPosition,
+		CoverURL:           m.CoverURL,
+		Description:        m.Description,
+		ScrapedAt:          s.opts.timeValue(bookData.ScrapedAt),
+		ReviewsFetched:     bookData.Fetched(),
+	}
+	if book.Genres == nil {
+		book.Genres = []string{}
+	}
+	for _, c := range m.Contributors {
+		book.Contributors = append(book.Contributors, jsonContributor{ID: c.ID, Name: c.Name, Role: c.Role, URL: c.URL})
+	}
+	return book
+}
+
+// toJSONReview converts a review to its JSON form
+func (s *JSONLStorage) toJSONReview(review models.Review) jsonReview {
+	tags := make([]jsonTag, 0, len(review.Tags))
+	for _, tag := range review.Tags {
+		tags = append(tags, jsonTag{Name: tag.Name, URL: tag.URL})
+	}
+
+	return jsonReview{
+		WorkID:                   review.WorkID,
+		BookURL:                  review.BookURL,
+		BookTitle:                review.BookTitle,
+		ReviewID:                 review.ReviewID,
+		ReviewURL:                review.ReviewURL,
+		ReviewerName:             review.ReviewerName,
+		Rating:                   review.Rating,
+		ReviewText:               review.ReviewText,
+		Language:                 review.Language,
+		LanguageConfidence:       review.LanguageConfidence,
+		FetchedLanguage:          review.FetchedLanguage,
+		CreatedAt:                s.opts.timeValue(review.CreatedAt),
+		UpdatedAt:                s.opts.timeValue(review.UpdatedAt),
+		LastRevisionAt:           s.opts.timeValue(review.LastRevisionAt),
+		ReviewerID:               review.ReviewerID,
+		ReviewerURL:              review.ReviewerURL,
+		ReviewerIsAuthor:         review.ReviewerIsAuthor,
+		ReviewerFollowersCount:   review.ReviewerFollowersCount,
+		ReviewerTextReviewsCount: review.ReviewerTextReviewsCount,
+		LikeCount:                review.LikeCount,
+		CommentCount:             review.CommentCount,
+		Spoiler:                  review.Spoiler,
+		Shelf:                    review.Shelf,
+		Tags:                     tags,
+	}
+}
+
+// appendJSONL appends each value as a single JSON line to outputPath and
+// syncs it to disk. The first time a file is appended to, a partial line left
+// by an interrupted write is trimmed. Paths ending in .gz or .zst get each
+// batch as its own compressed member, and a truncated member is trimmed.
+func (s *JSONLStorage) appendJSONL(outputPath string, values []any) error {
+	// Ensure directory exists
+	dir := filepath.Dir(outputPath)
+	if dir != "." {
+		if err := os.MkdirAll(dir, 0755); err != nil {
+			return fmt.Errorf("failed to create output directory: %w", err)
+		}
+	}
+
+	var buf strings.Builder
+	encoder := json.NewEncoder(&buf)
+	encoder.SetEscapeHTML(false)
+
+	for _, value := range values {
+		if err := encoder.Encode(value); err != nil {
+			return fmt.Errorf("failed to write record: %w", err)
+		}
+	}
+
+	file, err := os.OpenFile(outputPath, os.O_RDWR|os.O_CREATE, 0644)
+	if err != nil {
+		return fmt.Errorf("failed to open output file: %w", err)
+	}
+	defer file.Close()
+
+	info, err := file.Stat()
+	if err != nil {
+		return fmt.Errorf("failed to stat output file: %w", err)
+	}
+	size := info.Size()
+
+	s.mu.Lock()
+	checked := s.checked[outputPath]
+	s.mu.Unlock()
+
+	_, compression := SplitCompression(outputPath)
+	if size > 0 && !checked {
+		var end int64
+		if compression == CompressionNone {
+			end, err = recoverJSONL(file, size)
+		} else {
+			end, err = recoverCompressed(file, size, compression)
+		}
+		if err != nil {
+			return fmt.Errorf("%s: %w", outputPath, err)
+		}
+		if end < size {
+			if err := file.Truncate(end); err != nil {
+				return fmt.Errorf("failed to trim partial record: %w", err)
+			}
+			size = end
+		}
+	}
+
+	if _, err := file.Seek(size, io.SeekStart); err != nil {
+		return fmt.Errorf("failed to seek output file: %w", err)
+	}
+	if err := writeCompressed(file, compression, buf.String()); err != nil {
+		return fmt.Errorf("failed to write output file: %w", err)
+	}
+	if err := file.Sync(); err != nil {
+		return fmt.Errorf("failed to sync output file: %w", err)
+	}
+
+	s.mu.Lock()
+	s.checked[outputPath] = true
+	s.mu.Unlock()
+	return nil
+}
+
+// recoverJSONL returns the offset just past the last newline of a JSON Lines
+// file, where a line cut short by an interrupted write starts. An offset of 0
+// means not even the first line was complete.
+func recoverJSONL(file *os.File, size int64) (int64, error) {
+	chunk := make([]byte, 4096)
+	for end := size; end > 0; {
+		start := max(end-int64(len(chunk)), 0)
+		n, err := file.ReadAt(chunk[:end-start], start)
+		if err != nil && err != io.EOF {
+			return 0, fmt.Errorf("failed to read output file: %w", err)
+		}
+		if i := bytes.LastIndexByte(chunk[:n], '\n'); i >= 0 {
+			return start + int64(i) + 1, nil
+		}
+		end = start
+	}
+	return 0, nil
+}
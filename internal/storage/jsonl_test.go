@@ -0,0 +1,261 @@
+package storage
+
+import (
+	"bufio"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/rizkirmdhnnn/goodreadscrape/internal/models"
+)
+
+// readJSONLines decodes every line of a JSONL file into a generic map
+func readJSONLines(t *testing.T, path string) []map[string]any {
+	t.Helper()
+
+	file, err := os.Open(path)
+	if err != nil {
+		t.Fatalf("Failed to open output file: %v", err)
+	}
+	defer file.Close()
+
+	var lines []map[string]any
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		var line map[string]any
+		if err := json.Unmarshal(scanner.Bytes(), &line); err != nil {
+			t.Fatalf("Invalid JSON line %q: %v", scanner.Text(), err)
+		}
+		lines = append(lines, line)
+	}
+	return lines
+}
+
+func TestJSONLStorage_SaveReviews(t *testing.T) {
+	tmpPath := filepath.Join(t.TempDir(), "out", "reviews.jsonl")
+
+	s := NewJSONLStorage(WithTimeFormat(TimeFormatRFC3339))
+	five := 5
+	reviews := []models.Review{
+		{
+			WorkID:       "kca://work/1",
+			BookURL:      "http://example.com/book1",
+			ReviewID:     "kca://review/1",
+			ReviewerName: "John Doe",
+			Rating:       &five,
+			ReviewText:   "Great <b>book</b>!",
+			CreatedAt:    time.Date(2023, 1, 1, 12, 30, 0, 0, time.UTC),
+			Tags:         []models.ReviewTag{{Name: "favorites"}},
+
+			Language:           "en",
+			LanguageConfidence: 0.5,
+			FetchedLanguage:    "id",
+		},
+		{
+			WorkID:       "kca://work/1",
+			BookURL:      "http://example.com/book1",
+			ReviewID:     "kca://review/2",
+			ReviewerName: "Jane Doe",
+			ReviewText:   "Unrated",
+		},
+	}
+
+	if err := s.SaveReviews(reviews[:1], tmpPath); err != nil {
+		t.Fatalf("SaveReviews failed: %v", err)
+	}
+	// A second batch must append to the same file
+	if err := s.SaveReviews(reviews[1:], tmpPath); err != nil {
+		t.Fatalf("SaveReviews failed: %v", err)
+	}
+
+	lines := readJSONLines(t, tmpPath)
+	if len(lines) != 2 {
+		t.Fatalf("Expected 2 lines, got %d", len(lines))
+	}
+
+	first := lines[0]
+	if first["review_id"] != "kca://review/1" {
+		t.Errorf("Expected review_id kca://review/1, got %v", first["review_id"])
+	}
+	if first["rating"] != float64(5) {
+		t.Errorf("Expected rating 5, got %v", first["rating"])
+	}
+	if first["review_text"] != "Great <b>book</b>!" {
+		t.Errorf("Expected unescaped review text, got %v", first["review_text"])
+	}
+	if first["created_at"] != "2023-01-01T12:30:00Z" {
+		t.Errorf("Expected created_at 2023-01-01T12:30:00Z, got %v", first["created_at"])
+	}
+	if tags, ok := first["tags"].([]any); !ok || len(tags) != 1 {
+		t.Errorf("Expected 1 tag, got %v", first["tags"])
+	}
+	if first["language"] != "en" || first["language_confidence"] != 0.5 || first["fetched_language"] != "id" {
+		t.Errorf("Expected language en (0.5) fetched as id, got %v (%v) fetched as %v",
+			first["language"], first["language_confidence"], first["fetched_language"])
+	}
+
+	second := lines[1]
+	if second["rating"] != nil {
+		t.Errorf("Expected null rating, got %v", second["rating"])
+	}
+	if second["created_at"] != nil {
+		t.Errorf("Expected null created_at, got %v", second["created_at"])
+	}
+	if tags, ok := second["tags"].([]any); !ok || len(tags) != 0 {
+		t.Errorf("Expected empty tags array, got %v", second["tags"])
+	}
+}
+
+func TestJSONLStorage_EpochMillis(t *testing.T) {
+	tmpPath := filepath.Join(t.TempDir(), "reviews.jsonl")
+
+	s := NewJSONLStorage(WithTimeFormat(TimeFormatEpochMillis))
+	reviews := []models.Review{{ReviewID: "1", CreatedAt: time.UnixMilli(1390817944000)}}
+
+	if err := s.SaveReviews(reviews, tmpPath); err != nil {
+		t.Fatalf("SaveReviews failed: %v", err)
+	}
+
+	lines := readJSONLines(t, tmpPath)
+	if lines[0]["created_at"] != float64(1390817944000) {
+		t.Errorf("Expected numeric created_at, got %v", lines[0]["created_at"])
+	}
+}
+
+func TestJSONLStorage_SaveBookData(t *testing.T) {
+	tmpPath := filepath.Join(t.TempDir(), "reviews_books.jsonl")
+
+	s := NewJSONLStorage()
+	bookData := models.BookData{
+		Metadata: models.BookMetadata{
+			WorkID:             "kca://work/1",
+			BookID:             "1362193",
+			Title:              "Laskar Pelangi",
+			Author:             "Andrea Hirata",
+			Contributors:       []models.Contributor{{Name: "Andrea Hirata", Role: "Author"}},
+			RatingDistribution: [5]int{1, 2, 3, 4, 5},
+			URL:                "https://www.goodreads.com/book/show/1362193",
+			FirstPublished:     time.Date(2005, 1, 1, 0, 0, 0, 0, time.UTC),
+		},
+		Reviews:   []models.Review{{ReviewID: "kca://review/1"}, {ReviewID: "kca://review/2"}},
+		ScrapedAt: time.Date(2024, 5, 1, 10, 0, 0, 0, time.UTC),
+	}
+
+	if err := s.SaveBookData(bookData, tmpPath); err != nil {
+		t.Fatalf("SaveBookData failed: %v", err)
+	}
+
+	lines := readJSONLines(t, tmpPath)
+	if len(lines) != 1 {
+		t.Fatalf("Expected 1 line, got %d", len(lines))
+	}
+
+	book := lines[0]
+	if book["title"] != "Laskar Pelangi" {
+		t.Errorf("Expected title Laskar Pelangi, got %v", book["title"])
+	}
+	if book["first_published"] != "2005-01-01" {
+		t.Errorf("Expected first_published 2005-01-01, got %v", book["first_published"])
+	}
+	if book["scraped_at"] != "2024-05-01T10:00:00Z" {
+		t.Errorf("Expected scraped_at 2024-05-01T10:00:00Z, got %v", book["scraped_at"])
+	}
+	if book["reviews_fetched"] != float64(2) {
+		t.Errorf("Expected reviews_fetched 2, got %v", book["reviews_fetched"])
+	}
+	if reviews, ok := book["reviews"].([]any); !ok || len(reviews) != 2 {
+		t.Errorf("Expected 2 embedded reviews, got %v", book["reviews"])
+	}
+	if dist, ok := book["rating_distribution"].([]any); !ok || len(dist) != 5 {
+		t.Errorf("Expected 5 rating buckets, got %v", book["rating_distribution"])
+	}
+}
+
+func TestJSONLStorage_Recovery(t *testing.T) {
+	line := `{"review_id":"r1"}` + "\n"
+	long := `{"review_id":"r0","review_text":"` + strings.Repeat("x", 5000) + `"}` + "\n"
+
+	tests := []struct {
+		name     string
+		existing string
+		wantRows int // Lines after appending one review
+	}{
+		{"Empty file", "", 1},
+		{"Partial first line is trimmed", `{"review_id":"r`, 1},
+		{"Complete file is appended to", line, 2},
+		{"Partial last line is trimmed", line + `{"review_id":"r2","rev`, 2},
+		{"Partial line after a long one is trimmed", long + strings.Repeat("y", 5000), 2},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tmpPath := filepath.Join(t.TempDir(), "reviews.jsonl")
+			if err := os.WriteFile(tmpPath, []byte(tt.existing), 0644); err != nil {
+				t.Fatal(err)
+			}
+
+			s := NewJSONLStorage()
+			if err := s.SaveReviews([]models.Review{{ReviewID: "new"}}, tmpPath); err != nil {
+				t.Fatalf("SaveReviews failed: %v", err)
+			}
+
+			lines := readJSONLines(t, tmpPath)
+			if len(lines) != tt.wantRows {
+				t.Fatalf("Expected %d lines, got %d", tt.wantRows, len(lines))
+			}
+			if lines[len(lines)-1]["review_id"] != "new" {
+				t.Errorf("Expected the new review last, got %v", lines[len(lines)-1])
+			}
+		})
+	}
+}
+
+func TestResolveFormat(t *testing.T) {
+	tests := []struct {
+		name     string
+		format   string
+		path     string
+		expected Format
+		wantErr  bool
+	}{
+		{"Default CSV", "", "results/out.csv", FormatCSV, false},
+		{"Unknown extension", "", "results/out.txt", FormatCSV, false},
+		{"JSONL extension", "", "results/out.jsonl", FormatJSONL, false},
+		{"NDJSON extension", "", "results/out.NDJSON", FormatJSONL, false},
+		{"SQLite extension", "", "results/out.sqlite", FormatSQLite, false},
+		{"Flag overrides extension", "jsonl", "results/out.csv", FormatJSONL, false},
+		{"Gzip CSV", "", "results/out.csv.gz", FormatCSV, false},
+		{"Zstd JSONL", "", "results/out.jsonl.zst", FormatJSONL, false},
+		{"Compressed SQLite", "", "results/out.db.gz", "", true},
+		{"Compressed Parquet by flag", "parquet", "results/out.zst", "", true},
+		{"Unknown format", "xml", "results/out.csv", "", true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := ResolveFormat(tt.format, tt.path)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("ResolveFormat() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if got != tt.expected {
+				t.Errorf("Expected %q, got %q", tt.expected, got)
+			}
+		})
+	}
+}
+
+func TestNew(t *testing.T) {
+	s, err := New(FormatJSONL)
+	if err != nil {
+		t.Fatalf("New failed: %v", err)
+	}
+	if _, ok := s.(*JSONLStorage); !ok {
+		t.Error("New(jsonl) did not return *JSONLStorage")
+	}
+	if _, err := New("xml"); err == nil {
+		t.Error("Expected error for unknown format")
+	}
+}
@@ -0,0 +1,325 @@
+package storage
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"slices"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/rizkirmdhnnn/goodreadscrape/internal/models"
+)
+
+// KnownReviewsReader is implemented by backends that can read back the
+// reviews they saved, so that incremental runs fetch only newer ones
+type KnownReviewsReader interface {
+	// ReadKnownReviews adds the reviews saved at outputPath to known. An
+	// output that does not exist yet holds no reviews.
+	ReadKnownReviews(outputPath string, known models.KnownReviews) error
+}
+
+// ReadKnownReviews adds the reviews in the CSV file at outputPath to known.
+// The file needs WorkID and ReviewID columns; ReviewDate and FetchedLanguage
+// are used if present.
+func (s *CSVStorage) ReadKnownReviews(outputPath string, known models.KnownReviews) error {
+	r, err := openOutput(outputPath)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	defer r.Close()
+
+	reader := csv.NewReader(r)
+	reader.Comma = s.opts.csvDialect.Delimiter
+	reader.FieldsPerRecord = -1
+
+	header, err := reader.Read()
+	if err == io.EOF {
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("%s: failed to read header: %w", outputPath, err)
+	}
+	header[0] = strings.TrimPrefix(header[0], utf8BOM)
+	workCol := slices.Index(header, "WorkID")
+	reviewCol := slices.Index(header, "ReviewID")
+	dateCol := slices.Index(header, "ReviewDate")
+	languageCol := slices.Index(header, "FetchedLanguage")
+	if workCol < 0 || reviewCol < 0 {
+		return fmt.Errorf("%s has no WorkID and ReviewID columns to find saved reviews by", outputPath)
+	}
+
+	for {
+		record, err := reader.Read()
+		if err == io.EOF {
+			return nil
+		}
+		if err != nil {
+			// Only the last record can be cut short by an interrupted write
+			if _, next := reader.Read(); next == io.EOF {
+				return nil
+			}
+			return fmt.Errorf("%s: malformed CSV: %w", outputPath, err)
+		}
+		if len(record) != len(header) {
+			continue
+		}
+
+		var createdAt time.Time
+		if dateCol >= 0 {
+			createdAt = parseTimeValue(record[dateCol])
+		}
+		var language string
+		if languageCol >= 0 {
+			language = record[languageCol]
+		}
+		known.Add(record[workCol], record[reviewCol], language, createdAt)
+	}
+}
+
+// ReadKnownReviews adds the reviews in the JSON Lines file at outputPath to known
+func (s *JSONLStorage) ReadKnownReviews(outputPath string, known models.KnownReviews) error {
+	r, err := openOutput(outputPath)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	defer r.Close()
+
+	reader := bufio.NewReader(r)
+	for {
+		line, err := reader.ReadBytes('\n')
+		if err == io.EOF {
+			// A line without its newline was cut short by an interrupted write
+			return nil
+		}
+		if err != nil {
+			return fmt.Errorf("failed to read %s: %w", outputPath, err)
+		}
+		line = bytes.TrimSpace(line)
+		if len(line) == 0 {
+			continue
+		}
+
+		var review struct {
+			WorkID          string `json:"work_id"`
+			ReviewID        string `json:"review_id"`
+			CreatedAt       any    `json:"created_at"`
+			FetchedLanguage string `json:"fetched_language"`
+		}
+		if err := json.Unmarshal(line, &review); err != nil {
+			return fmt.Errorf("%s: malformed JSON line: %w", outputPath, err)
+		}
+		known.Add(review.WorkID, review.ReviewID, review.FetchedLanguage, parseTimeValue(review.CreatedAt))
+	}
+}
+
+// ReadKnownReviews adds the reviews in the database at outputPath to known
+func (s *SQLiteStorage) ReadKnownReviews(outputPath string, known models.KnownReviews) error {
+	if _, err := os.Stat(outputPath); errors.Is(err, os.ErrNotExist) {
+		return nil
+	}
+	db, err := s.open(outputPath)
+	if err != nil {
+		return err
+	}
+
+	rows, err := db.Query("SELECT work_id, review_id, fetched_language, created_at FROM reviews")
+	if err != nil {
+		return fmt.Errorf("failed to read reviews: %w", err)
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var workID, reviewID, language string
+		var createdAt any
+		if err := rows.Scan(&workID, &reviewID, &language, &createdAt); err != nil {
+			return fmt.Errorf("failed to read reviews: %w", err)
+		}
+		known.Add(workID, reviewID, language, parseTimeValue(createdAt))
+	}
+	return rows.Err()
+}
+
+// ReadKnownReviews adds the reviews of every book in the manifest of
+// outputPath's run directory to known
+func (s *PartitionedStorage) ReadKnownReviews(outputPath string, known models.KnownReviews) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	run, err := s.run(outputPath)
+	if err != nil {
+		return err
+	}
+
+	store, err := New(s.format, s.opts...)
+	if err != nil {
+		return err
+	}
+	if closer, ok := store.(io.Closer); ok {
+		defer closer.Close()
+	}
+	reader, ok := store.(KnownReviewsReader)
+	if !ok {
+		return fmt.Errorf("saved reviews cannot be read back from %s outputs", s.format)
+	}
+
+	for _, entry := range run.manifest.Books {
+		if entry.ReviewsFile == "" {
+			continue
+		}
+		if err := reader.ReadKnownReviews(filepath.Join(run.dir, filepath.FromSlash(entry.ReviewsFile)), known); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// stateFile is the JSON form of a state file
+type stateFile struct {
+	UpdatedAt time.Time            `json:"updated_at"`
+	Works     map[string]stateWork `json:"works"`
+}
+
+// stateWork is the JSON form of models.KnownWork. Latest is that of reviews
+// fetched in every language, the only one state files used to record.
+type stateWork struct {
+	Latest           *time.Time           `json:"latest_created_at,omitempty"`
+	LatestByLanguage map[string]time.Time `json:"latest_created_at_by_language,omitempty"`
+	ReviewIDs        []string             `json:"review_ids"`
+}
+
+// ReadStateFile adds the reviews recorded in the state file at path to known.
+// A state file that does not exist yet records no reviews.
+func ReadStateFile(path string, known models.KnownReviews) error {
+	data, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("failed to read state file: %w", err)
+	}
+
+	var state stateFile
+	if err := json.Unmarshal(data, &state); err != nil {
+		return fmt.Errorf("%s is not a state file: %w", path, err)
+	}
+	for workID, work := range state.Works {
+		into := known.Work(workID)
+		for _, reviewID := range work.ReviewIDs {
+			into.ReviewIDs[reviewID] = true
+		}
+		if work.Latest != nil {
+			into.RaiseLatest("", *work.Latest)
+		}
+		for language, latest := range work.LatestByLanguage {
+			into.RaiseLatest(language, latest)
+		}
+	}
+	return nil
+}
+
+// WriteStateFile replaces the state file at path with the reviews in all of
+// knowns, so that the next incremental run fetches only newer ones
+func WriteStateFile(path string, knowns ...models.KnownReviews) error {
+	merged := models.KnownReviews{}
+	for _, known := range knowns {
+		for workID, work := range known {
+			into := merged.Work(workID)
+			for reviewID := range work.ReviewIDs {
+				into.ReviewIDs[reviewID] = true
+			}
+			for language, latest := range work.Latest {
+				into.RaiseLatest(language, latest)
+			}
+		}
+	}
+
+	state := stateFile{UpdatedAt: time.Now().UTC(), Works: make(map[string]stateWork, len(merged))}
+	for workID, work := range merged {
+		entry := stateWork{ReviewIDs: make([]string, 0, len(work.ReviewIDs))}
+		for language, latest := range work.Latest {
+			if language == "" {
+				latest = latest.UTC()
+				entry.Latest = &latest
+				continue
+			}
+			if entry.LatestByLanguage == nil {
+				entry.LatestByLanguage = make(map[string]time.Time)
+			}
+			entry.LatestByLanguage[language] = latest.UTC()
+		}
+		for reviewID := range work.ReviewIDs {
+			entry.ReviewIDs = append(entry.ReviewIDs, reviewID)
+		}
+		sort.Strings(entry.ReviewIDs)
+		state.Works[workID] = entry
+	}
+
+	data, err := json.Marshal(state)
+	if err != nil {
+		return fmt.Errorf("failed to encode state file: %w", err)
+	}
+	return writeFileAtomic(path, data)
+}
+
+// outputReader reads an output file, decompressed if needed
+type outputReader struct {
+	io.Reader
+	closers []io.Closer
+}
+
+func (r *outputReader) Close() error {
+	var errs []error
+	for _, closer := range r.closers {
+		errs = append(errs, closer.Close())
+	}
+	return errors.Join(errs...)
+}
+
+// openOutput opens an output file to read back what was saved, decompressing
+// it if its path is compressed. A compressed member truncated by an
+// interrupted write is left out.
+func openOutput(path string) (io.ReadCloser, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	_, compression := SplitCompression(path)
+	if compression == CompressionNone {
+		return file, nil
+	}
+
+	info, err := file.Stat()
+	if err != nil {
+		file.Close()
+		return nil, fmt.Errorf("failed to stat %s: %w", path, err)
+	}
+	end, err := recoverCompressed(file, info.Size(), compression)
+	if err != nil {
+		file.Close()
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+	if end == 0 {
+		return &outputReader{Reader: strings.NewReader(""), closers: []io.Closer{file}}, nil
+	}
+
+	decompressor, err := newDecompressor(io.NewSectionReader(file, 0, end), compression)
+	if err != nil {
+		file.Close()
+		return nil, fmt.Errorf("failed to read %s: %w", path, err)
+	}
+	return &outputReader{Reader: decompressor, closers: []io.Closer{decompressor, file}}, nil
+}
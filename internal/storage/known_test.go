@@ -0,0 +1,192 @@
+package storage
+
+import (
+	"io"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/rizkirmdhnnn/goodreadscrape/internal/models"
+)
+
+// knownReviewsFixture is saved by every backend and read back as known reviews
+var knownReviewsFixture = []models.Review{
+	{WorkID: "kca://work/w1", ReviewID: "r1", CreatedAt: time.Date(2024, 1, 2, 10, 0, 0, 0, time.UTC), FetchedLanguage: "id"},
+	{WorkID: "kca://work/w1", ReviewID: "r2", CreatedAt: time.Date(2024, 3, 4, 10, 0, 0, 0, time.UTC), FetchedLanguage: "id"},
+	{WorkID: "kca://work/w2", ReviewID: "r3"},
+}
+
+func TestReadKnownReviews(t *testing.T) {
+	tests := []struct {
+		name   string
+		file   string
+		newFn  func() (Storage, error)
+		latest time.Time // Latest of w1 in id, at the precision the output keeps
+	}{
+		{"CSV dates", "out.csv", func() (Storage, error) { return NewCSVStorage(WithTimeFormat(TimeFormatDate)), nil },
+			time.Date(2024, 3, 4, 0, 0, 0, 0, time.UTC)},
+		{"Gzip CSV", "out.csv.gz", func() (Storage, error) { return NewCSVStorage(WithTimeFormat(TimeFormatRFC3339)), nil },
+			time.Date(2024, 3, 4, 10, 0, 0, 0, time.UTC)},
+		{"JSON Lines epoch", "out.jsonl", func() (Storage, error) { return NewJSONLStorage(WithTimeFormat(TimeFormatEpochMillis)), nil },
+			time.Date(2024, 3, 4, 10, 0, 0, 0, time.UTC)},
+		{"Zstd JSON Lines", "out.jsonl.zst", func() (Storage, error) { return NewJSONLStorage(), nil },
+			time.Date(2024, 3, 4, 10, 0, 0, 0, time.UTC)},
+		{"SQLite", "out.db", func() (Storage, error) { return NewSQLiteStorage(WithTimeFormat(TimeFormatEpochMillis)), nil },
+			time.Date(2024, 3, 4, 10, 0, 0, 0, time.UTC)},
+		{"Partitioned CSV", "run.csv", func() (Storage, error) { return NewPartitionedStorage(FormatCSV) },
+			time.Date(2024, 3, 4, 10, 0, 0, 0, time.UTC)},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			output := filepath.Join(t.TempDir(), tt.file)
+			store, err := tt.newFn()
+			if err != nil {
+				t.Fatal(err)
+			}
+			if err := store.SaveReviews(knownReviewsFixture, output); err != nil {
+				t.Fatalf("SaveReviews failed: %v", err)
+			}
+			if closer, ok := store.(io.Closer); ok {
+				if err := closer.Close(); err != nil {
+					t.Fatalf("Close failed: %v", err)
+				}
+			}
+
+			// A fresh backend, as in the next run
+			store, _ = tt.newFn()
+			known := models.KnownReviews{}
+			if err := store.(KnownReviewsReader).ReadKnownReviews(output, known); err != nil {
+				t.Fatalf("ReadKnownReviews failed: %v", err)
+			}
+			if closer, ok := store.(io.Closer); ok {
+				closer.Close()
+			}
+
+			w1, w2 := known["kca://work/w1"], known["kca://work/w2"]
+			if w1 == nil || w2 == nil {
+				t.Fatalf("Expected both works, got %v", known)
+			}
+			if len(w1.ReviewIDs) != 2 || !w1.ReviewIDs["r1"] || !w1.ReviewIDs["r2"] || !w2.ReviewIDs["r3"] {
+				t.Errorf("Unexpected review IDs: %v, %v", w1.ReviewIDs, w2.ReviewIDs)
+			}
+			if len(w1.Latest) != 1 || !w1.Latest["id"].Equal(tt.latest) {
+				t.Errorf("Expected latest %v in id, got %v", tt.latest, w1.Latest)
+			}
+			if !w2.Latest[""].IsZero() {
+				t.Errorf("Expected no latest for undated reviews, got %v", w2.Latest)
+			}
+		})
+	}
+}
+
+func TestReadKnownReviews_MissingOutput(t *testing.T) {
+	known := models.KnownReviews{}
+	store := NewCSVStorage().(KnownReviewsReader)
+	if err := store.ReadKnownReviews(filepath.Join(t.TempDir(), "missing.csv"), known); err != nil {
+		t.Errorf("Expected no error for a missing output, got %v", err)
+	}
+	if len(known) != 0 {
+		t.Errorf("Expected no known reviews, got %d", len(known))
+	}
+}
+
+func TestReadKnownReviews_SkipsPartialRecord(t *testing.T) {
+	output := filepath.Join(t.TempDir(), "out.csv")
+	data := "WorkID,ReviewID\nw1,r1\nw1,\"r2"
+	if err := os.WriteFile(output, []byte(data), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	known := models.KnownReviews{}
+	store := NewCSVStorage(WithCSVColumns([]string{"WorkID", "ReviewID"})).(KnownReviewsReader)
+	if err := store.ReadKnownReviews(output, known); err != nil {
+		t.Fatalf("ReadKnownReviews failed: %v", err)
+	}
+	if len(known["w1"].ReviewIDs) != 1 {
+		t.Errorf("Expected only the complete record, got %v", known["w1"].ReviewIDs)
+	}
+}
+
+func TestReadKnownReviews_NeedsIDColumns(t *testing.T) {
+	output := filepath.Join(t.TempDir(), "out.csv")
+	store := NewCSVStorage(WithCSVColumns([]string{"BookTitle", "Rating"}))
+	if err := store.SaveReviews(knownReviewsFixture, output); err != nil {
+		t.Fatalf("SaveReviews failed: %v", err)
+	}
+
+	if err := store.(KnownReviewsReader).ReadKnownReviews(output, models.KnownReviews{}); err == nil {
+		t.Error("Expected an error for a file without WorkID and ReviewID columns")
+	}
+}
+
+func TestStateFile(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "state.json")
+
+	// A missing state file knows nothing
+	known := models.KnownReviews{}
+	if err := ReadStateFile(path, known); err != nil {
+		t.Fatalf("ReadStateFile failed: %v", err)
+	}
+	if len(known) != 0 {
+		t.Errorf("Expected no known reviews, got %d", len(known))
+	}
+
+	latest := time.Date(2024, 3, 4, 10, 0, 0, 0, time.UTC)
+	known.Add("w1", "r1", "id", latest.Add(-time.Hour))
+	known.Add("w1", "r0", "", latest.Add(-2*time.Hour))
+	added := models.KnownReviews{}
+	added.Add("w1", "r2", "en", latest)
+	added.Add("w2", "r3", "", time.Time{})
+	if err := WriteStateFile(path, known, added); err != nil {
+		t.Fatalf("WriteStateFile failed: %v", err)
+	}
+
+	read := models.KnownReviews{}
+	if err := ReadStateFile(path, read); err != nil {
+		t.Fatalf("ReadStateFile failed: %v", err)
+	}
+	w1 := read["w1"]
+	if w1 == nil || len(w1.ReviewIDs) != 3 || !w1.Latest["en"].Equal(latest) ||
+		!w1.Latest["id"].Equal(latest.Add(-time.Hour)) || !w1.Latest[""].Equal(latest.Add(-2*time.Hour)) {
+		t.Errorf("Unexpected w1: %+v", read["w1"])
+	}
+	if w2 := read["w2"]; w2 == nil || !w2.ReviewIDs["r3"] || !w2.Latest[""].IsZero() {
+		t.Errorf("Unexpected w2: %+v", read["w2"])
+	}
+
+	// State files without languages recorded the latest of every language
+	if err := os.WriteFile(path, []byte(`{"works":{"w1":{"latest_created_at":"2024-03-04T10:00:00Z","review_ids":["r1"]}}}`), 0644); err != nil {
+		t.Fatal(err)
+	}
+	read = models.KnownReviews{}
+	if err := ReadStateFile(path, read); err != nil {
+		t.Fatalf("ReadStateFile failed: %v", err)
+	}
+	if w1 := read["w1"]; w1 == nil || !w1.ReviewIDs["r1"] || !w1.Latest[""].Equal(latest) {
+		t.Errorf("Unexpected w1 from an older state file: %+v", read["w1"])
+	}
+}
+
+func TestParseTimeValue(t *testing.T) {
+	when := time.Date(2024, 3, 4, 10, 0, 0, 0, time.UTC)
+	tests := []struct {
+		input    any
+		expected time.Time
+	}{
+		{"2024-03-04T10:00:00Z", when},
+		{"2024-03-04", time.Date(2024, 3, 4, 0, 0, 0, 0, time.UTC)},
+		{"1709546400000", when},
+		{float64(1709546400000), when},
+		{int64(1709546400000), when},
+		{"", time.Time{}},
+		{nil, time.Time{}},
+	}
+
+	for _, tt := range tests {
+		if got := parseTimeValue(tt.input); !got.Equal(tt.expected) {
+			t.Errorf("parseTimeValue(%v) = %v, want %v", tt.input, got, tt.expected)
+		}
+	}
+}
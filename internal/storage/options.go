@@ -0,0 +1,118 @@
+package storage
+
+import (
+	"fmt"
+	"strconv"
+	"time"
+)
+
+// TimeFormat controls how timestamps are serialized by storage backends
+type TimeFormat string
+
+// Supported time formats
+const (
+	TimeFormatRFC3339     TimeFormat = "rfc3339"
+	TimeFormatDate        TimeFormat = "date"
+	TimeFormatEpochMillis TimeFormat = "epoch-ms"
+)
+
+// ParseTimeFormat validates a time format name
+func ParseTimeFormat(name string) (TimeFormat, error) {
+	switch f := TimeFormat(name); f {
+	case TimeFormatRFC3339, TimeFormatDate, TimeFormatEpochMillis:
+		return f, nil
+	default:
+		return "", fmt.Errorf("unknown time format %q (use %s, %s or %s)", name, TimeFormatRFC3339, TimeFormatDate, TimeFormatEpochMillis)
+	}
+}
+
+// Format renders t in UTC, or returns an empty string for the zero time
+func (f TimeFormat) Format(t time.Time) string {
+	if t.IsZero() {
+		return ""
+	}
+	t = t.UTC()
+
+	switch f {
+	case TimeFormatDate:
+		return t.Format("2006-01-02")
+	case TimeFormatEpochMillis:
+		return strconv.FormatInt(t.UnixMilli(), 10)
+	default:
+		return t.Format(time.RFC3339)
+	}
+}
+
+// options holds settings shared by storage backends
+type options struct {
+	timeFormat TimeFormat
+
+	// CSV settings
+	csvDialect CSVDialect
+	csvColumns []string
+
+	// Parquet settings
+	parquetCodec ParquetCodec
+	rowGroupSize int64
+}
+
+// Option configures a storage backend
+type Option func(*options)
+
+// WithTimeFormat sets how review and scrape timestamps are serialized
+func WithTimeFormat(format TimeFormat) Option {
+	return func(o *options) {
+		if format != "" {
+			o.timeFormat = format
+		}
+	}
+}
+
+// timeValue renders t for typed backends: nil when unset, int64 for epoch
+// milliseconds and a string otherwise
+func (o options) timeValue(t time.Time) any {
+	if t.IsZero() {
+		return nil
+	}
+	if o.timeFormat == TimeFormatEpochMillis {
+		return t.UnixMilli()
+	}
+	return o.timeFormat.Format(t)
+}
+
+// parseTimeValue reads back a timestamp saved in any of the time formats, as
+// text or a number of epoch milliseconds. Unreadable values are zero.
+func parseTimeValue(v any) time.Time {
+	switch v := v.(type) {
+	case int64:
+		return time.UnixMilli(v).UTC()
+	case float64:
+		return time.UnixMilli(int64(v)).UTC()
+	case []byte:
+		return parseTimeValue(string(v))
+	case string:
+		if ms, err := strconv.ParseInt(v, 10, 64); err == nil {
+			return time.UnixMilli(ms).UTC()
+		}
+		for _, layout := range []string{time.RFC3339, "2006-01-02"} {
+			if t, err := time.Parse(layout, v); err == nil {
+				return t.UTC()
+			}
+		}
+	}
+	return time.Time{}
+}
+
+// newOptions applies opts over the defaults
+func newOptions(opts []Option) options {
+	o := options{
+		timeFormat:   TimeFormatRFC3339,
+		csvDialect:   DefaultCSVDialect,
+		parquetCodec: ParquetCodecSnappy,
+		rowGroupSize: DefaultRowGroupSize,
+	}
+	for _, opt := range opts {
+		opt(&o)
+	}
+	return o
+}
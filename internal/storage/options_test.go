@@ -0,0 +1,37 @@
+package storage
+
+import (
+	"testing"
+	"time"
+)
+
+func TestTimeFormat_Format(t *testing.T) {
+	ts := time.Date(2014, 1, 27, 17, 19, 4, 0, time.FixedZone("WIB", 7*3600))
+
+	tests := []struct {
+		format   TimeFormat
+		expected string
+	}{
+		{TimeFormatRFC3339, "2014-01-27T10:19:04Z"},
+		{TimeFormatDate, "2014-01-27"},
+		{TimeFormatEpochMillis, "1390817944000"},
+	}
+
+	for _, tt := range tests {
+		if got := tt.format.Format(ts); got != tt.expected {
+			t.Errorf("%s.Format() = %q, want %q", tt.format, got, tt.expected)
+		}
+		if got := tt.format.Format(time.Time{}); got != "" {
+			t.Errorf("%s.Format(zero) = %q, want empty", tt.format, got)
+		}
+	}
+}
+
+func TestParseTimeFormat(t *testing.T) {
+	if _, err := ParseTimeFormat("epoch-ms"); err != nil {
+		t.Errorf("ParseTimeFormat(epoch-ms) failed: %v", err)
+	}
+	if _, err := ParseTimeFormat("unix"); err == nil {
+		t.Error("Expected error for unknown format")
+	}
+}
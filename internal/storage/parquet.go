@@ -0,0 +1,376 @@
+package storage
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+
+	"github.com/parquet-go/parquet-go"
+	"github.com/parquet-go/parquet-go/compress"
+
+	"github.com/rizkirmdhnnn/goodreadscrape/internal/models"
+)
+
+// ParquetCodec names the compression codec used for Parquet column chunks
+type ParquetCodec string
+
+// Supported Parquet codecs
+const (
+	ParquetCodecSnappy ParquetCodec = "snappy"
+	ParquetCodecZstd   ParquetCodec = "zstd"
+	ParquetCodecGzip   ParquetCodec = "gzip"
+	ParquetCodecNone   ParquetCodec = "none"
+)
+
+// DefaultRowGroupSize is the default number of rows buffered per Parquet row group
+const DefaultRowGroupSize = 10000
+
+// ParseParquetCodec validates a Parquet codec name
+func ParseParquetCodec(name string) (ParquetCodec, error) {
+	switch c := ParquetCodec(name); c {
+	case ParquetCodecSnappy, ParquetCodecZstd, ParquetCodecGzip, ParquetCodecNone:
+		return c, nil
+	default:
+		return "", fmt.Errorf("unknown parquet codec %q (use %s, %s, %s or %s)", name, ParquetCodecSnappy, ParquetCodecZstd, ParquetCodecGzip, ParquetCodecNone)
+	}
+}
+
+// codec returns the parquet-go codec for c
+func (c ParquetCodec) codec() compress.Codec {
+	switch c {
+	case ParquetCodecZstd:
+		return &parquet.Zstd
+	case ParquetCodecGzip:
+		return &parquet.Gzip
+	case ParquetCodecNone:
+		return &parquet.Uncompressed
+	default:
+		return &parquet.Snappy
+	}
+}
+
+// WithParquetCodec sets the compression codec of Parquet output
+func WithParquetCodec(codec ParquetCodec) Option {
+	return func(o *options) {
+		if codec != "" {
+			o.parquetCodec = codec
+		}
+	}
+}
+
+// WithRowGroupSize sets how many rows the Parquet writer buffers before
+// flushing a row group to disk, which bounds memory use on large runs
+func WithRowGroupSize(rows int64) Option {
+	return func(o *options) {
+		if rows > 0 {
+			o.rowGroupSize = rows
+		}
+	}
+}
+
+// parquetTag is the Parquet form of models.ReviewTag
+type parquetTag struct {
+	Name string `parquet:"name"`
+	URL  string `parquet:"url"`
+}
+
+// parquetReview is the Parquet row schema for reviews
+type parquetReview struct {
+	ReviewID     string `parquet:"review_id"`
+	WorkID       string `parquet:"work_id,dict"`
+	BookURL      string `parquet:"book_url,dict"`
+	BookTitle    string `parquet:"book_title,dict"`
+	ReviewURL    string `parquet:"review_url"`
+	ReviewerName string `parquet:"reviewer_name"`
+	Rating       *int32 `parquet:"rating,optional"`
+	ReviewText   string `parquet:"review_text"`
+
+	Language           string  `parquet:"language,dict"`
+	LanguageConfidence float64 `parquet:"language_confidence"`
+	FetchedLanguage    string  `parquet:"fetched_language,dict"`
+
+	CreatedAt      *time.Time `parquet:"created_at,optional,timestamp(millisecond:utc)"`
+	UpdatedAt      *time.Time `parquet:"updated_at,optional,timestamp(millisecond:utc)"`
+	LastRevisionAt *time.Time `parquet:"last_revision_at,optional,timestamp(millisecond:utc)"`
+
+	ReviewerID               string `parquet:"reviewer_id"`
+	ReviewerURL              string `parquet:"reviewer_url"`
+	ReviewerIsAuthor         bool   `parquet:"reviewer_is_author"`
+	ReviewerFollowersCount   int64  `parquet:"reviewer_followers_count"`
+	ReviewerTextReviewsCount int64  `parquet:"reviewer_text_reviews_count"`
+
+	LikeCount    int64        `parquet:"like_count"`
+	CommentCount int64        `parquet:"comment_count"`
+	Spoiler      bool         `parquet:"spoiler"`
+	Shelf        string       `parquet:"shelf,dict"`
+	Tags         []parquetTag `parquet:"tags,list"`
+}
+
+// parquetContributor is the Parquet form of models.Contributor
+type parquetContributor struct {
+	ID   string `parquet:"id"`
+	Name string `parquet:"name"`
+	Role string `parquet:"role"`
+	URL  string `parquet:"url"`
+}
+
+// parquetBook is the Parquet row schema for books
+type parquetBook struct {
+	WorkID             string               `parquet:"work_id"`
+	BookID             string               `parquet:"book_id"`
+	BookURL            string               `parquet:"book_url"`
+	Title              string               `parquet:"title"`
+	Author             string               `parquet:"author"`
+	Contributors       []parquetContributor `parquet:"contributors,list"`
+	AverageRating      float64              `parquet:"average_rating"`
+	RatingsCount       int64                `parquet:"ratings_count"`
+	TextReviewsCount   int64                `parquet:"text_reviews_count"`
+	RatingDistribution []int64              `parquet:"rating_distribution,list"` // 1 to 5 stars
+
+	ISBN10         string `parquet:"isbn10"`
+	ISBN13         string `parquet:"isbn13"`
+	ASIN           string `parquet:"asin"`
+	Language       string `parquet:"language"`
+	PageCount      int32  `parquet:"page_count"`
+	Format         string `parquet:"format"`
+	Publisher      string `parquet:"publisher"`
+	EditionDate    *int32 `parquet:"edition_date,optional,date"` // Days since the Unix epoch
+	FirstPublished *int32 `parquet:"first_published,optional,date"`
+
+	Genres         []string `parquet:"genres,list"`
+	SeriesName     string   `parquet:"series_name"`
+	SeriesPosition string   `parquet:"series_position"`
+	CoverURL       string   `parquet:"cover_url"`
+	Description    string   `parquet:"description"`
+
+	ScrapedAt      *time.Time `parquet:"scraped_at,optional,timestamp(millisecond:utc)"`
+	ReviewsFetched int32      `parquet:"reviews_fetched"`
+}
+
+// parquetSink is an open Parquet file receiving rows of type T
+type parquetSink[T any] struct {
+	file   *os.File
+	writer *parquet.GenericWriter[T]
+}
+
+// close writes the Parquet footer and closes the file
+func (p *parquetSink[T]) close() error {
+	if err := p.writer.Close(); err != nil {
+		p.file.Close()
+		return err
+	}
+	return p.file.Close()
+}
+
+// ParquetStorage implements Storage interface for Apache Parquet output.
+// A Parquet file is only readable once its footer is written, so files stay
+// open across batches and are finalized by Close.
+type ParquetStorage struct {
+	opts options
+
+	mu      sync.Mutex
+	reviews map[string]*parquetSink[parquetReview]
+	books   map[string]*parquetSink[parquetBook]
+}
+
+// NewParquetStorage creates a new Parquet storage instance
+func NewParquetStorage(opts ...Option) Storage {
+	return &ParquetStorage{
+		opts:    newOptions(opts),
+		reviews: make(map[string]*parquetSink[parquetReview]),
+		books:   make(map[string]*parquetSink[parquetBook]),
+	}
+}
+
+// SaveReviews buffers reviews for the Parquet file at outputPath
+func (s *ParquetStorage) SaveReviews(reviews []models.Review, outputPath string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	sink, err := openParquetSink(s.reviews, outputPath, s.opts)
+	if err != nil {
+		return err
+	}
+
+	rows := make([]parquetReview, 0, len(reviews))
+	for _, review := range reviews {
+		tags := make([]parquetTag, 0, len(review.Tags))
+		for _, tag := range review.Tags {
+			tags = append(tags, parquetTag{Name: tag.Name, URL: tag.URL})
+		}
+
+		var rating *int32
+		if review.Rating != nil {
+			r := int32(*review.Rating)
+			rating = &r
+		}
+
+		rows = append(rows, parquetReview{
+			ReviewID:                 review.ReviewID,
+			WorkID:                   review.WorkID,
+			BookURL:                  review.BookURL,
+			BookTitle:                review.BookTitle,
+			ReviewURL:                review.ReviewURL,
+			ReviewerName:             review.ReviewerName,
+			Rating:                   rating,
+			ReviewText:               review.ReviewText,
+			Language:                 review.Language,
+			LanguageConfidence:       review.LanguageConfidence,
+			FetchedLanguage:          review.FetchedLanguage,
+			CreatedAt:                optionalTime(review.CreatedAt),
+			UpdatedAt:                optionalTime(review.UpdatedAt),
+			LastRevisionAt:           optionalTime(review.LastRevisionAt),
+			ReviewerID:               review.ReviewerID,
+			ReviewerURL:              review.ReviewerURL,
+			ReviewerIsAuthor:         review.ReviewerIsAuthor,
+			ReviewerFollowersCount:   int64(review.ReviewerFollowersCount),
+			ReviewerTextReviewsCount: int64(review.ReviewerTextReviewsCount),
+			LikeCount:                int64(review.LikeCount),
+			CommentCount:             int64(review.CommentCount),
+			Spoiler:                  review.Spoiler,
+			Shelf:                    review.Shelf,
+			Tags:                     tags,
+		})
+	}
+
+	if _, err := sink.writer.Write(rows); err != nil {
+		return fmt.Errorf("failed to write reviews: %w", err)
+	}
+	return nil
+}
+
+// SaveBookData buffers one row of book metadata for the Parquet file at outputPath
+func (s *ParquetStorage) SaveBookData(bookData models.BookData, outputPath string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	sink, err := openParquetSink(s.books, outputPath, s.opts)
+	if err != nil {
+		return err
+	}
+
+	m := bookData.Metadata
+	contributors := make([]parquetContributor, 0, len(m.Contributors))
+	for _, c := range m.Contributors {
+		contributors = append(contributors, parquetContributor{ID: c.ID, Name: c.Name, Role: c.Role, URL: c.URL})
+	}
+	distribution := make([]int64, len(m.RatingDistribution))
+	for i, count := range m.RatingDistribution {
+		distribution[i] = int64(count)
+	}
+
+	row := parquetBook{
+		WorkID:             m.WorkID,
+		BookID:             m.BookID,
+		BookURL:            m.URL,
+		Title:              m.Title,
+		Author:             m.Author,
+		Contributors:       contributors,
+		AverageRating:      m.AverageRating,
+		RatingsCount:       int64(m.RatingsCount),
+		TextReviewsCount:   int64(m.TextReviewsCount),
+		RatingDistribution: distribution,
+		ISBN10:             m.ISBN10,
+		ISBN13:             m.ISBN13,
+		ASIN:               m.ASIN,
+		Language:           m.Language,
+		PageCount:          int32(m.PageCount),
+		Format:             m.Format,
+		Publisher:          m.Publisher,
+		EditionDate:        optionalDate(m.EditionDate),
+		FirstPublished:     optionalDate(m.FirstPublished),
+		Genres:             m.Genres,
+		SeriesName:         m.SeriesName,
+		SeriesPosition:     m.SeriesPosition,
+		CoverURL:           m.CoverURL,
+		Description:        m.Description,
+		ScrapedAt:          optionalTime(bookData.ScrapedAt),
+		ReviewsFetched:     int32(bookData.Fetched()),
+	}
+
+	if _, err := sink.writer.Write([]parquetBook{row}); err != nil {
+		return fmt.Errorf("failed to write book: %w", err)
+	}
+	return nil
+}
+
+// Close flushes buffered rows and writes the footer of every open file
+func (s *ParquetStorage) Close() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	var errs []error
+	for path, sink := range s.reviews {
+		if err := sink.close(); err != nil {
+			errs = append(errs, fmt.Errorf("failed to close %s: %w", path, err))
+		}
+		delete(s.reviews, path)
+	}
+	for path, sink := range s.books {
+		if err := sink.close(); err != nil {
+			errs = append(errs, fmt.Errorf("failed to close %s: %w", path, err))
+		}
+		delete(s.books, path)
+	}
+	return errors.Join(errs...)
+}
+
+// openParquetSink returns the open sink for path, creating the file on first
+// use. Parquet files cannot be appended to, so an existing file is an error.
+func openParquetSink[T any](sinks map[string]*parquetSink[T], path string, opts options) (*parquetSink[T], error) {
+	if sink, ok := sinks[path]; ok {
+		return sink, nil
+	}
+
+	// Ensure directory exists
+	dir := filepath.Dir(path)
+	if dir != "." {
+		if err := os.MkdirAll(dir, 0755); err != nil {
+			return nil, fmt.Errorf("failed to create output directory: %w", err)
+		}
+	}
+
+	file, err := os.OpenFile(path, os.O_CREATE|os.O_EXCL|os.O_WRONLY, 0644)
+	if err != nil {
+		if errors.Is(err, os.ErrExist) {
+			return nil, fmt.Errorf("%s already exists and Parquet files cannot be appended to; choose a new output file", path)
+		}
+		return nil, fmt.Errorf("failed to create output file: %w", err)
+	}
+
+	sink := &parquetSink[T]{
+		file: file,
+		writer: parquet.NewGenericWriter[T](file,
+			parquet.Compression(opts.parquetCodec.codec()),
+			parquet.MaxRowsPerRowGroup(opts.rowGroupSize),
+		),
+	}
+	sinks[path] = sink
+	return sink, nil
+}
+
+// optionalDate returns t as days since the Unix epoch, or nil for the zero time
+func optionalDate(t time.Time) *int32 {
+	if t.IsZero() {
+		return nil
+	}
+	// Round down, so times before the epoch fall on their own day
+	seconds := t.UTC().Unix()
+	if seconds < 0 {
+		seconds -= 86399
+	}
+	days := int32(seconds / 86400)
+	return &days
+}
+
+// optionalTime returns nil for the zero time so it is stored as null
+func optionalTime(t time.Time) *time.Time {
+	if t.IsZero() {
+		return nil
+	}
+	t = t.UTC()
+	return &t
+}
@@ -0,0 +1,208 @@
+package storage
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/parquet-go/parquet-go"
+
+	"github.com/rizkirmdhnnn/goodreadscrape/internal/models"
+)
+
+// openParquet opens a written Parquet file for inspection
+func openParquet(t *testing.T, path string) *parquet.File {
+	t.Helper()
+
+	file, err := os.Open(path)
+	if err != nil {
+		t.Fatalf("Failed to open output file: %v", err)
+	}
+	t.Cleanup(func() { file.Close() })
+
+	info, err := file.Stat()
+	if err != nil {
+		t.Fatal(err)
+	}
+	pf, err := parquet.OpenFile(file, info.Size())
+	if err != nil {
+		t.Fatalf("Invalid Parquet file: %v", err)
+	}
+	return pf
+}
+
+func TestParquetStorage_SaveReviews(t *testing.T) {
+	tmpPath := filepath.Join(t.TempDir(), "out", "reviews.parquet")
+
+	s := NewParquetStorage(WithParquetCodec(ParquetCodecZstd))
+	five := 5
+	created := time.Date(2023, 1, 1, 12, 30, 0, 0, time.UTC)
+
+	// Two batches, as app.Run saves one per book
+	err := s.SaveReviews([]models.Review{{
+		ReviewID:  "kca://review/1",
+		WorkID:    "kca://work/1",
+		Rating:    &five,
+		CreatedAt: created,
+		Tags:      []models.ReviewTag{{Name: "favorites"}, {Name: "indonesia"}},
+	}}, tmpPath)
+	if err != nil {
+		t.Fatalf("SaveReviews failed: %v", err)
+	}
+	if err := s.SaveReviews([]models.Review{{ReviewID: "kca://review/2", WorkID: "kca://work/2"}}, tmpPath); err != nil {
+		t.Fatalf("SaveReviews failed: %v", err)
+	}
+	if err := s.(*ParquetStorage).Close(); err != nil {
+		t.Fatalf("Close failed: %v", err)
+	}
+
+	rows, err := parquet.ReadFile[parquetReview](tmpPath)
+	if err != nil {
+		t.Fatalf("Failed to read rows: %v", err)
+	}
+	if len(rows) != 2 {
+		t.Fatalf("Expected 2 rows, got %d", len(rows))
+	}
+
+	first := rows[0]
+	if first.Rating == nil || *first.Rating != 5 {
+		t.Errorf("Expected rating 5, got %v", first.Rating)
+	}
+	if first.CreatedAt == nil || !first.CreatedAt.Equal(created) {
+		t.Errorf("Expected created_at %v, got %v", created, first.CreatedAt)
+	}
+	if len(first.Tags) != 2 || first.Tags[1].Name != "indonesia" {
+		t.Errorf("Expected 2 tags, got %v", first.Tags)
+	}
+
+	second := rows[1]
+	if second.Rating != nil {
+		t.Errorf("Expected null rating, got %d", *second.Rating)
+	}
+	if second.CreatedAt != nil {
+		t.Errorf("Expected null created_at, got %v", second.CreatedAt)
+	}
+}
+
+func TestParquetStorage_RowGroups(t *testing.T) {
+	tmpPath := filepath.Join(t.TempDir(), "reviews.parquet")
+
+	s := NewParquetStorage(WithRowGroupSize(2))
+	reviews := make([]models.Review, 5)
+	for i := range reviews {
+		reviews[i] = models.Review{ReviewID: string(rune('a' + i))}
+	}
+	if err := s.SaveReviews(reviews, tmpPath); err != nil {
+		t.Fatalf("SaveReviews failed: %v", err)
+	}
+	if err := s.(*ParquetStorage).Close(); err != nil {
+		t.Fatalf("Close failed: %v", err)
+	}
+
+	pf := openParquet(t, tmpPath)
+	if pf.NumRows() != 5 {
+		t.Errorf("Expected 5 rows, got %d", pf.NumRows())
+	}
+	if groups := len(pf.RowGroups()); groups != 3 {
+		t.Errorf("Expected 3 row groups, got %d", groups)
+	}
+}
+
+func TestParquetStorage_SaveBookData(t *testing.T) {
+	tmpPath := filepath.Join(t.TempDir(), "reviews_books.parquet")
+
+	s := NewParquetStorage()
+	bookData := models.BookData{
+		Metadata: models.BookMetadata{
+			WorkID:             "kca://work/1",
+			Title:              "Laskar Pelangi",
+			RatingDistribution: [5]int{1, 2, 3, 4, 5},
+			Genres:             []string{"Fiction", "Indonesian Literature"},
+			FirstPublished:     time.Date(2005, 1, 1, 0, 0, 0, 0, time.UTC),
+		},
+		Reviews:   []models.Review{{ReviewID: "1"}},
+		ScrapedAt: time.Date(2024, 5, 1, 10, 0, 0, 0, time.UTC),
+	}
+	if err := s.SaveBookData(bookData, tmpPath); err != nil {
+		t.Fatalf("SaveBookData failed: %v", err)
+	}
+	if err := s.(*ParquetStorage).Close(); err != nil {
+		t.Fatalf("Close failed: %v", err)
+	}
+
+	rows, err := parquet.ReadFile[parquetBook](tmpPath)
+	if err != nil {
+		t.Fatalf("Failed to read rows: %v", err)
+	}
+	if len(rows) != 1 {
+		t.Fatalf("Expected 1 row, got %d", len(rows))
+	}
+
+	book := rows[0]
+	if book.Title != "Laskar Pelangi" || book.ReviewsFetched != 1 {
+		t.Errorf("Unexpected book row: %+v", book)
+	}
+	if len(book.RatingDistribution) != 5 || book.RatingDistribution[4] != 5 {
+		t.Errorf("Expected rating distribution [1 2 3 4 5], got %v", book.RatingDistribution)
+	}
+	if len(book.Genres) != 2 {
+		t.Errorf("Expected 2 genres, got %v", book.Genres)
+	}
+	// 2005-01-01 is 12784 days after the Unix epoch
+	if book.FirstPublished == nil || *book.FirstPublished != 12784 {
+		t.Errorf("Expected first_published 12784, got %v", book.FirstPublished)
+	}
+	if book.EditionDate != nil {
+		t.Errorf("Expected null edition_date, got %v", book.EditionDate)
+	}
+}
+
+func TestOptionalDate(t *testing.T) {
+	tests := []struct {
+		name     string
+		date     time.Time
+		expected int32
+	}{
+		{"Epoch", time.Date(1970, 1, 1, 0, 0, 0, 0, time.UTC), 0},
+		{"After the epoch", time.Date(2005, 1, 1, 8, 0, 0, 0, time.UTC), 12784},
+		{"Day before the epoch", time.Date(1969, 12, 31, 0, 0, 0, 0, time.UTC), -1},
+		{"Midday before the epoch", time.Date(1969, 12, 31, 12, 0, 0, 0, time.UTC), -1},
+		{"Last second before the epoch", time.Date(1969, 12, 31, 23, 59, 59, 0, time.UTC), -1},
+		{"Old publication", time.Date(1813, 1, 28, 0, 0, 0, 0, time.UTC), -57316},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := optionalDate(tt.date)
+			if got == nil {
+				t.Fatalf("optionalDate(%s) = nil, want %d", tt.date, tt.expected)
+			}
+			if *got != tt.expected {
+				t.Errorf("optionalDate(%s) = %d, want %d", tt.date, *got, tt.expected)
+			}
+		})
+	}
+}
+
+func TestParquetStorage_RefusesExistingFile(t *testing.T) {
+	tmpPath := filepath.Join(t.TempDir(), "reviews.parquet")
+	if err := os.WriteFile(tmpPath, []byte("existing"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	s := NewParquetStorage()
+	defer s.(*ParquetStorage).Close()
+	if err := s.SaveReviews([]models.Review{{ReviewID: "1"}}, tmpPath); err == nil {
+		t.Error("Expected error for existing file")
+	}
+}
+
+func TestParseParquetCodec(t *testing.T) {
+	if _, err := ParseParquetCodec("zstd"); err != nil {
+		t.Errorf("ParseParquetCodec(zstd) failed: %v", err)
+	}
+	if _, err := ParseParquetCodec("lzo"); err == nil {
+		t.Error("Expected error for unknown codec")
+	}
+}
@@ -0,0 +1,392 @@
+package storage
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/url"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/rizkirmdhnnn/goodreadscrape/internal/models"
+)
+
+// Files written to every partitioned run and book directory
+const (
+	manifestFile = "manifest.json"
+	bookFile     = "book.json"
+)
+
+// PartitionedStorage writes every book to its own directory under a run
+// directory named after the output path, e.g. for results/run.csv:
+//
+//	results/run/<work-id-or-slug>/reviews.csv
+//	results/run/<work-id-or-slug>/book.json
+//	results/run/manifest.json
+//
+// Reviews are written by a backend of the wrapped format, one per book, which
+// is closed once the book's status is recorded. The manifest lists every book
+// with its status, row count and reviews file checksum, and is rewritten as
+// each book finishes.
+type PartitionedStorage struct {
+	format Format
+	opts   []Option
+	json   *JSONLStorage // Encodes book.json
+
+	mu   sync.Mutex
+	runs map[string]*partitionRun // By run directory
+}
+
+// partitionRun is the state of one run directory
+type partitionRun struct {
+	dir         string
+	reviewsFile string // File name of every book's reviews, e.g. reviews.csv.gz
+	manifest    partitionManifest
+	stores      map[string]Storage // Open backends by book directory
+}
+
+// partitionManifest is the JSON form of manifest.json
+type partitionManifest struct {
+	Format    Format           `json:"format"`
+	UpdatedAt time.Time        `json:"updated_at"`
+	Books     []*manifestEntry `json:"books"`
+}
+
+// manifestEntry is one book of a partitioned run. Paths are relative to the
+// run directory.
+type manifestEntry struct {
+	Dir         string              `json:"dir"`
+	URL         string              `json:"url,omitempty"`
+	WorkID      string              `json:"work_id,omitempty"`
+	Title       string              `json:"title,omitempty"`
+	Status      models.ScrapeStatus `json:"status,omitempty"`
+	Error       string              `json:"error,omitempty"`
+	ReviewsFile string              `json:"reviews_file,omitempty"`
+	Rows        int                 `json:"rows"` // Reviews written, across runs resuming the book
+	SHA256      string              `json:"sha256,omitempty"`
+}
+
+// NewPartitionedStorage creates a partitioned storage writing reviews in format
+func NewPartitionedStorage(format Format, opts ...Option) (Storage, error) {
+	// Fail early on formats New cannot create
+	if _, err := New(format, opts...); err != nil {
+		return nil, err
+	}
+	return &PartitionedStorage{
+		format: format,
+		opts:   opts,
+		json:   &JSONLStorage{opts: newOptions(opts)},
+		runs:   make(map[string]*partitionRun),
+	}, nil
+}
+
+// PartitionDir returns the run directory of a partitioned output path, e.g.
+// results/run.csv.gz -> results/run
+func PartitionDir(outputPath string) string {
+	base, _ := SplitCompression(outputPath)
+	return strings.TrimSuffix(base, filepath.Ext(base))
+}
+
+// SaveReviews appends reviews to the reviews file of each review's book
+func (s *PartitionedStorage) SaveReviews(reviews []models.Review, outputPath string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	run, err := s.run(outputPath)
+	if err != nil {
+		return err
+	}
+
+	// Group by book, keeping the order books first appear in
+	var keys []string
+	groups := make(map[string][]models.Review)
+	for _, review := range reviews {
+		key := partitionKey(review.WorkID, review.BookURL)
+		if _, ok := groups[key]; !ok {
+			keys = append(keys, key)
+		}
+		groups[key] = append(groups[key], review)
+	}
+
+	for _, key := range keys {
+		store, err := run.store(key, s.format, s.opts)
+		if err != nil {
+			return err
+		}
+		if err := store.SaveReviews(groups[key], filepath.Join(run.dir, key, run.reviewsFile)); err != nil {
+			return err
+		}
+
+		entry := run.entry(key)
+		entry.ReviewsFile = path.Join(key, run.reviewsFile)
+		entry.Rows += len(groups[key])
+	}
+	return nil
+}
+
+// SaveBookData writes the book's metadata to book.json in its directory. For
+// SQLite, which keeps books next to reviews, it is also saved to the database.
+func (s *PartitionedStorage) SaveBookData(bookData models.BookData, outputPath string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	run, err := s.run(outputPath)
+	if err != nil {
+		return err
+	}
+
+	key := partitionKey(bookData.Metadata.WorkID, bookData.Metadata.URL)
+	data, err := json.MarshalIndent(s.json.toJSONBookMetadata(bookData), "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to encode book: %w", err)
+	}
+	if err := writeFileAtomic(filepath.Join(run.dir, key, bookFile), data); err != nil {
+		return err
+	}
+
+	entry := run.entry(key)
+	entry.URL = bookData.Metadata.URL
+	entry.WorkID = bookData.Metadata.WorkID
+	entry.Title = bookData.Metadata.Title
+
+	store, err := run.store(key, s.format, s.opts)
+	if err != nil {
+		return err
+	}
+	reviewsPath := filepath.Join(run.dir, key, run.reviewsFile)
+	switch store := store.(type) {
+	case *CSVStorage:
+		// Lets the reviews file carry book columns
+		store.rememberBook(bookData)
+	case *SQLiteStorage:
+		return store.SaveBookData(bookData, reviewsPath)
+	}
+	return nil
+}
+
+// RecordStatus finishes a book: its backend is closed, the checksum of its
+// reviews file is taken and the manifest is rewritten
+func (s *PartitionedStorage) RecordStatus(result BookResult, outputPath string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	run, err := s.run(outputPath)
+	if err != nil {
+		return err
+	}
+
+	// Books that failed before their page was read only have the input URL
+	key := partitionKey(result.Book.WorkID, result.Book.URL, result.URL)
+
+	entry := run.entry(key)
+	if entry.URL == "" {
+		entry.URL = result.URL
+	}
+	entry.Status = result.Status
+	entry.Error = result.Error
+
+	closeErr := run.finish(key)
+	return errors.Join(closeErr, run.writeManifest())
+}
+
+// Close finishes every book still open and writes the manifests
+func (s *PartitionedStorage) Close() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	var errs []error
+	for _, run := range s.runs {
+		for key := range run.stores {
+			errs = append(errs, run.finish(key))
+		}
+		errs = append(errs, run.writeManifest())
+	}
+	return errors.Join(errs...)
+}
+
+// run returns the state of outputPath's run directory, loading the manifest
+// of an earlier run into the same directory
+func (s *PartitionedStorage) run(outputPath string) (*partitionRun, error) {
+	dir := PartitionDir(outputPath)
+	if run, ok := s.runs[dir]; ok {
+		return run, nil
+	}
+
+	reviewsFile := "reviews" + strings.TrimPrefix(outputPath, dir)
+	if reviewsFile == "reviews" {
+		reviewsFile += s.format.Extension()
+	}
+
+	run := &partitionRun{
+		dir:         dir,
+		reviewsFile: reviewsFile,
+		manifest:    partitionManifest{Format: s.format, Books: []*manifestEntry{}},
+		stores:      make(map[string]Storage),
+	}
+
+	data, err := os.ReadFile(filepath.Join(dir, manifestFile))
+	switch {
+	case err == nil:
+		if err := json.Unmarshal(data, &run.manifest); err != nil {
+			return nil, fmt.Errorf("failed to read manifest of %s: %w", dir, err)
+		}
+		if run.manifest.Format != s.format {
+			return nil, fmt.Errorf("%s holds a %s run; use a new output path", dir, run.manifest.Format)
+		}
+	case !errors.Is(err, os.ErrNotExist):
+		return nil, fmt.Errorf("failed to read manifest of %s: %w", dir, err)
+	}
+
+	s.runs[dir] = run
+	return run, nil
+}
+
+// entry returns the manifest entry of a book directory, adding it if new
+func (r *partitionRun) entry(key string) *manifestEntry {
+	for _, entry := range r.manifest.Books {
+		if entry.Dir == key {
+			return entry
+		}
+	}
+	entry := &manifestEntry{Dir: key}
+	r.manifest.Books = append(r.manifest.Books, entry)
+	return entry
+}
+
+// store returns the open backend of a book directory, creating it if needed
+func (r *partitionRun) store(key string, format Format, opts []Option) (Storage, error) {
+	if store, ok := r.stores[key]; ok {
+		return store, nil
+	}
+	if err := os.MkdirAll(filepath.Join(r.dir, key), 0755); err != nil {
+		return nil, fmt.Errorf("failed to create book directory: %w", err)
+	}
+	store, err := New(format, opts...)
+	if err != nil {
+		return nil, err
+	}
+	r.stores[key] = store
+	return store, nil
+}
+
+// finish closes the backend of a book directory and records the checksum of
+// its reviews file
+func (r *partitionRun) finish(key string) error {
+	var closeErr error
+	if closer, ok := r.stores[key].(io.Closer); ok {
+		closeErr = closer.Close()
+	}
+	delete(r.stores, key)
+
+	entry := r.entry(key)
+	if entry.ReviewsFile == "" {
+		return closeErr
+	}
+	sum, err := fileSHA256(filepath.Join(r.dir, filepath.FromSlash(entry.ReviewsFile)))
+	if err != nil {
+		return errors.Join(closeErr, err)
+	}
+	entry.SHA256 = sum
+	return closeErr
+}
+
+// writeManifest replaces manifest.json with the current state of the run
+func (r *partitionRun) writeManifest() error {
+	r.manifest.UpdatedAt = time.Now().UTC()
+	data, err := json.MarshalIndent(r.manifest, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to encode manifest: %w", err)
+	}
+	return writeFileAtomic(filepath.Join(r.dir, manifestFile), data)
+}
+
+// partitionKey returns the directory name of a book: the last part of its
+// work ID, or else the slug of the first of its URLs that has one, e.g.
+// 12345.Laskar_Pelangi
+func partitionKey(workID string, bookURLs ...string) string {
+	if workID != "" {
+		if key := sanitizeKey(workID[strings.LastIndex(workID, "/")+1:]); key != "" {
+			return key
+		}
+	}
+	for _, bookURL := range bookURLs {
+		parsed, err := url.Parse(bookURL)
+		if err != nil || parsed.Path == "" {
+			continue
+		}
+		p := strings.TrimSuffix(strings.TrimSuffix(parsed.Path, "/"), "/reviews")
+		if key := sanitizeKey(path.Base(p)); key != "" && key != "-" {
+			return key
+		}
+	}
+	return "unknown"
+}
+
+// sanitizeKey replaces characters that are unsafe in directory names
+func sanitizeKey(key string) string {
+	key = strings.Map(func(r rune) rune {
+		switch {
+		case r >= 'a' && r <= 'z', r >= 'A' && r <= 'Z', r >= '0' && r <= '9', r == '.', r == '-', r == '_':
+			return r
+		default:
+			return '-'
+		}
+	}, key)
+	return strings.TrimLeft(key, ".")
+}
+
+// fileSHA256 returns the hex SHA-256 checksum of a file
+func fileSHA256(path string) (string, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return "", fmt.Errorf("failed to checksum %s: %w", path, err)
+	}
+	defer file.Close()
+
+	hash := sha256.New()
+	if _, err := io.Copy(hash, file); err != nil {
+		return "", fmt.Errorf("failed to checksum %s: %w", path, err)
+	}
+	return hex.EncodeToString(hash.Sum(nil)), nil
+}
+
+// writeFileAtomic replaces path with data, so readers never see a partly
+// written file
+func writeFileAtomic(path string, data []byte) error {
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return fmt.Errorf("failed to create output directory: %w", err)
+	}
+
+	tmp, err := os.CreateTemp(filepath.Dir(path), "."+filepath.Base(path)+".*")
+	if err != nil {
+		return fmt.Errorf("failed to write %s: %w", path, err)
+	}
+	defer os.Remove(tmp.Name())
+
+	if err := tmp.Chmod(0644); err != nil {
+		tmp.Close()
+		return fmt.Errorf("failed to write %s: %w", path, err)
+	}
+	if _, err := tmp.Write(append(data, '\n')); err != nil {
+		tmp.Close()
+		return fmt.Errorf("failed to write %s: %w", path, err)
+	}
+	if err := tmp.Sync(); err != nil {
+		tmp.Close()
+		return fmt.Errorf("failed to sync %s: %w", path, err)
+	}
+	if err := tmp.Close(); err != nil {
+		return fmt.Errorf("failed to write %s: %w", path, err)
+	}
+	if err := os.Rename(tmp.Name(), path); err != nil {
+		return fmt.Errorf("failed to write %s: %w", path, err)
+	}
+	return nil
+}
@@ -0,0 +1,192 @@
+package storage
+
+import (
+	"database/sql"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/rizkirmdhnnn/goodreadscrape/internal/models"
+)
+
+// readManifest decodes the manifest of a partitioned run directory
+func readManifest(t *testing.T, dir string) partitionManifest {
+	t.Helper()
+
+	data, err := os.ReadFile(filepath.Join(dir, manifestFile))
+	if err != nil {
+		t.Fatalf("Failed to read manifest: %v", err)
+	}
+	var manifest partitionManifest
+	if err := json.Unmarshal(data, &manifest); err != nil {
+		t.Fatalf("Invalid manifest: %v", err)
+	}
+	return manifest
+}
+
+func TestPartitionDir(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected string
+	}{
+		{"results/run.csv", "results/run"},
+		{"results/run.jsonl.gz", "results/run"},
+		{"results/run", "results/run"},
+	}
+
+	for _, tt := range tests {
+		if got := PartitionDir(tt.input); got != tt.expected {
+			t.Errorf("PartitionDir(%q) = %q, want %q", tt.input, got, tt.expected)
+		}
+	}
+}
+
+func TestPartitionKey(t *testing.T) {
+	tests := []struct {
+		name     string
+		workID   string
+		urls     []string
+		expected string
+	}{
+		{"Work ID", "kca://work/amzn1.gr.work.v1.abc", []string{"https://www.goodreads.com/book/show/1"}, "amzn1.gr.work.v1.abc"},
+		{"Book URL slug", "", []string{"https://www.goodreads.com/book/show/12345.Laskar_Pelangi/reviews"}, "12345.Laskar_Pelangi"},
+		{"Later URL", "", []string{"", "https://www.goodreads.com/book/show/7-title"}, "7-title"},
+		{"Unsafe characters", "", []string{"https://www.goodreads.com/book/show/1%3A%20title"}, "1--title"},
+		{"Nothing to go on", "", nil, "unknown"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := partitionKey(tt.workID, tt.urls...); got != tt.expected {
+				t.Errorf("partitionKey() = %q, want %q", got, tt.expected)
+			}
+		})
+	}
+}
+
+func TestPartitionedStorage_WritesBookDirectories(t *testing.T) {
+	output := filepath.Join(t.TempDir(), "run.csv.gz")
+	runDir := PartitionDir(output)
+
+	store, err := NewPartitionedStorage(FormatCSV)
+	if err != nil {
+		t.Fatal(err)
+	}
+	partitioned := store.(*PartitionedStorage)
+
+	book := models.BookData{Metadata: models.BookMetadata{
+		WorkID: "kca://work/w1",
+		URL:    "https://www.goodreads.com/book/show/1.One",
+		Title:  "One",
+	}}
+	if err := store.SaveBookData(book, output); err != nil {
+		t.Fatalf("SaveBookData failed: %v", err)
+	}
+	reviews := []models.Review{
+		{ReviewID: "r1", WorkID: "kca://work/w1"},
+		{ReviewID: "r2", WorkID: "kca://work/w1"},
+	}
+	if err := store.SaveReviews(reviews, output); err != nil {
+		t.Fatalf("SaveReviews failed: %v", err)
+	}
+	if err := partitioned.RecordStatus(BookResult{URL: book.Metadata.URL, Book: book.Metadata, Status: models.StatusComplete}, output); err != nil {
+		t.Fatalf("RecordStatus failed: %v", err)
+	}
+
+	// A book that failed before its page was read
+	failed := BookResult{URL: "https://www.goodreads.com/book/show/2.Two", Status: models.StatusFailed, Error: "not found"}
+	if err := partitioned.RecordStatus(failed, output); err != nil {
+		t.Fatalf("RecordStatus failed: %v", err)
+	}
+	if err := partitioned.Close(); err != nil {
+		t.Fatalf("Close failed: %v", err)
+	}
+
+	content := readCompressed(t, filepath.Join(runDir, "w1", "reviews.csv.gz"), CompressionGzip)
+	if got := len(content); got == 0 {
+		t.Fatal("Expected reviews in the book directory")
+	}
+	var bookJSON map[string]any
+	data, err := os.ReadFile(filepath.Join(runDir, "w1", bookFile))
+	if err != nil {
+		t.Fatalf("Failed to read book.json: %v", err)
+	}
+	if err := json.Unmarshal(data, &bookJSON); err != nil || bookJSON["title"] != "One" {
+		t.Errorf("Unexpected book.json: %s (%v)", data, err)
+	}
+
+	manifest := readManifest(t, runDir)
+	if len(manifest.Books) != 2 {
+		t.Fatalf("Expected 2 books in the manifest, got %d", len(manifest.Books))
+	}
+	first, second := manifest.Books[0], manifest.Books[1]
+	if first.Dir != "w1" || first.Rows != 2 || first.Status != models.StatusComplete || first.ReviewsFile != "w1/reviews.csv.gz" {
+		t.Errorf("Unexpected first entry: %+v", first)
+	}
+	sum, _ := fileSHA256(filepath.Join(runDir, "w1", "reviews.csv.gz"))
+	if first.SHA256 != sum {
+		t.Errorf("Expected checksum %s, got %s", sum, first.SHA256)
+	}
+	if second.Dir != "2.Two" || second.Status != models.StatusFailed || second.Error != "not found" || second.ReviewsFile != "" {
+		t.Errorf("Unexpected second entry: %+v", second)
+	}
+}
+
+func TestPartitionedStorage_ContinuesEarlierRun(t *testing.T) {
+	output := filepath.Join(t.TempDir(), "run.jsonl")
+	result := BookResult{Book: models.BookMetadata{WorkID: "kca://work/w1"}, Status: models.StatusPartial}
+
+	for i, id := range []string{"r1", "r2"} {
+		store, _ := NewPartitionedStorage(FormatJSONL)
+		partitioned := store.(*PartitionedStorage)
+		if err := store.SaveReviews([]models.Review{{ReviewID: id, WorkID: "kca://work/w1"}}, output); err != nil {
+			t.Fatalf("SaveReviews failed: %v", err)
+		}
+		if i == 1 {
+			result.Status = models.StatusComplete
+		}
+		if err := partitioned.RecordStatus(result, output); err != nil {
+			t.Fatalf("RecordStatus failed: %v", err)
+		}
+	}
+
+	manifest := readManifest(t, PartitionDir(output))
+	if len(manifest.Books) != 1 || manifest.Books[0].Rows != 2 || manifest.Books[0].Status != models.StatusComplete {
+		t.Errorf("Expected one complete book with 2 rows, got %+v", manifest.Books)
+	}
+
+	csvStore, _ := NewPartitionedStorage(FormatCSV)
+	if err := csvStore.SaveReviews([]models.Review{{ReviewID: "r3"}}, PartitionDir(output)+".csv"); err == nil {
+		t.Error("Expected error for a run directory of another format")
+	}
+}
+
+func TestPartitionedStorage_SQLiteKeepsBooks(t *testing.T) {
+	output := filepath.Join(t.TempDir(), "run.db")
+	store, _ := NewPartitionedStorage(FormatSQLite)
+
+	book := models.BookData{Metadata: models.BookMetadata{WorkID: "kca://work/w1", Title: "One"}}
+	if err := store.SaveBookData(book, output); err != nil {
+		t.Fatalf("SaveBookData failed: %v", err)
+	}
+	if err := store.SaveReviews([]models.Review{{ReviewID: "r1", WorkID: "kca://work/w1"}}, output); err != nil {
+		t.Fatalf("SaveReviews failed: %v", err)
+	}
+	if err := store.(*PartitionedStorage).Close(); err != nil {
+		t.Fatalf("Close failed: %v", err)
+	}
+
+	db, err := sql.Open("sqlite", filepath.Join(PartitionDir(output), "w1", "reviews.db"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer db.Close()
+
+	var books, reviews int
+	db.QueryRow("SELECT COUNT(*) FROM books").Scan(&books)
+	db.QueryRow("SELECT COUNT(*) FROM reviews").Scan(&reviews)
+	if books != 1 || reviews != 1 {
+		t.Errorf("Expected 1 book and 1 review in the book's database, got %d and %d", books, reviews)
+	}
+}
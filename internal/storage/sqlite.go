@@ -0,0 +1,391 @@
+package storage
+
+import (
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+
+	_ "modernc.org/sqlite" // Pure Go SQLite driver
+
+	"github.com/rizkirmdhnnn/goodreadscrape/internal/models"
+)
+
+// SQLiteStorage implements Storage interface for an embedded SQLite database.
+// Books are keyed on work ID and reviews on review ID, so re-scraping a book
+// updates its rows instead of duplicating them.
+type SQLiteStorage struct {
+	opts options
+
+	mu  sync.Mutex
+	dbs map[string]*sql.DB // Open databases by path
+}
+
+// NewSQLiteStorage creates a new SQLite storage instance
+func NewSQLiteStorage(opts ...Option) Storage {
+	return &SQLiteStorage{
+		opts: newOptions(opts),
+		dbs:  make(map[string]*sql.DB),
+	}
+}
+
+// sqliteMigrations are applied in order on open; the database's user_version
+// records how many have run. Append new migrations, never edit existing ones.
+var sqliteMigrations = []string{
+	`CREATE TABLE books (
+		work_id            TEXT PRIMARY KEY,
+		book_id            TEXT NOT NULL,
+		book_url           TEXT NOT NULL,
+		title              TEXT NOT NULL,
+		author             TEXT NOT NULL,
+		contributors       TEXT NOT NULL, -- JSON array
+		average_rating     REAL NOT NULL,
+		ratings_count      INTEGER NOT NULL,
+		text_reviews_count INTEGER NOT NULL,
+		ratings_1          INTEGER NOT NULL,
+		ratings_2          INTEGER NOT NULL,
+		ratings_3          INTEGER NOT NULL,
+		ratings_4          INTEGER NOT NULL,
+		ratings_5          INTEGER NOT NULL,
+		isbn10             TEXT NOT NULL,
+		isbn13             TEXT NOT NULL,
+		asin               TEXT NOT NULL,
+		language           TEXT NOT NULL,
+		page_count         INTEGER NOT NULL,
+		format             TEXT NOT NULL,
+		publisher          TEXT NOT NULL,
+		edition_date       TEXT,
+		first_published    TEXT,
+		genres             TEXT NOT NULL, -- JSON array
+		series_name        TEXT NOT NULL,
+		series_position    TEXT NOT NULL,
+		cover_url          TEXT NOT NULL,
+		description        TEXT NOT NULL,
+		scraped_at,
+		reviews_fetched    INTEGER NOT NULL,
+		first_seen_at      NOT NULL,
+		last_seen_at       NOT NULL
+	);
+	CREATE TABLE reviews (
+		review_id                   TEXT PRIMARY KEY,
+		work_id                     TEXT NOT NULL,
+		book_url                    TEXT NOT NULL,
+		book_title                  TEXT NOT NULL,
+		review_url                  TEXT NOT NULL,
+		reviewer_name               TEXT NOT NULL,
+		rating                      INTEGER,
+		review_text                 TEXT NOT NULL,
+		language                    TEXT NOT NULL,
+		created_at,
+		updated_at,
+		last_revision_at,
+		reviewer_id                 TEXT NOT NULL,
+		reviewer_url                TEXT NOT NULL,
+		reviewer_is_author          INTEGER NOT NULL,
+		reviewer_followers_count    INTEGER NOT NULL,
+		reviewer_text_reviews_count INTEGER NOT NULL,
+		like_count                  INTEGER NOT NULL,
+		comment_count               INTEGER NOT NULL,
+		spoiler                     INTEGER NOT NULL,
+		shelf                       TEXT NOT NULL,
+		tags                        TEXT NOT NULL, -- JSON array
+		first_seen_at               NOT NULL,
+		last_seen_at                NOT NULL
+	);
+	CREATE INDEX reviews_work_id ON reviews (work_id);`,
+	`ALTER TABLE reviews ADD COLUMN language_confidence REAL NOT NULL DEFAULT 0;
+	ALTER TABLE reviews ADD COLUMN fetched_language TEXT NOT NULL DEFAULT '';`,
+}
+
+// bookColumns and reviewColumns list the upserted columns, excluding the
+// first_seen_at/last_seen_at bookkeeping columns
+var (
+	bookColumns = []string{
+		"work_id", "book_id", "book_url", "title", "author", "contributors",
+		"average_rating", "ratings_count", "text_reviews_count",
+		"ratings_1", "ratings_2", "ratings_3", "ratings_4", "ratings_5",
+		"isbn10", "isbn13", "asin", "language", "page_count", "format", "publisher",
+		"edition_date", "first_published", "genres", "series_name", "series_position",
+		"cover_url", "description", "scraped_at", "reviews_fetched",
+	}
+	reviewColumns = []string{
+		"review_id", "work_id", "book_url", "book_title", "review_url", "reviewer_name",
+		"rating", "review_text", "language", "created_at", "updated_at", "last_revision_at",
+		"reviewer_id", "reviewer_url", "reviewer_is_author", "reviewer_followers_count",
+		"reviewer_text_reviews_count", "like_count", "comment_count", "spoiler", "shelf", "tags",
+		"language_confidence", "fetched_language",
+	}
+)
+
+// SaveReviews upserts reviews into the reviews table of the database at outputPath
+func (s *SQLiteStorage) SaveReviews(reviews []models.Review, outputPath string) error {
+	db, err := s.open(outputPath)
+	if err != nil {
+		return err
+	}
+
+	tx, err := db.Begin()
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	stmt, err := tx.Prepare(upsertSQL("reviews", "review_id", reviewColumns))
+	if err != nil {
+		return fmt.Errorf("failed to prepare review upsert: %w", err)
+	}
+	defer stmt.Close()
+
+	now := seenAt(time.Now())
+	for _, review := range reviews {
+		if review.ReviewID == "" {
+			return fmt.Errorf("review by %q on %s has no review ID", review.ReviewerName, review.BookURL)
+		}
+
+		tags := make([]jsonTag, 0, len(review.Tags))
+		for _, tag := range review.Tags {
+			tags = append(tags, jsonTag{Name: tag.Name, URL: tag.URL})
+		}
+		tagsJSON, err := json.Marshal(tags)
+		if err != nil {
+			return fmt.Errorf("failed to encode tags: %w", err)
+		}
+
+		var rating any
+		if review.Rating != nil {
+			rating = *review.Rating
+		}
+
+		_, err = stmt.Exec(
+			review.ReviewID,
+			review.WorkID,
+			review.BookURL,
+			review.BookTitle,
+			review.ReviewURL,
+			review.ReviewerName,
+			rating,
+			review.ReviewText,
+			review.Language,
+			s.opts.timeValue(review.CreatedAt),
+			s.opts.timeValue(review.UpdatedAt),
+			s.opts.timeValue(review.LastRevisionAt),
+			review.ReviewerID,
+			review.ReviewerURL,
+			review.ReviewerIsAuthor,
+			review.ReviewerFollowersCount,
+			review.ReviewerTextReviewsCount,
+			review.LikeCount,
+			review.CommentCount,
+			review.Spoiler,
+			review.Shelf,
+			string(tagsJSON),
+			review.LanguageConfidence,
+			review.FetchedLanguage,
+			now,
+			now,
+		)
+		if err != nil {
+			return fmt.Errorf("failed to upsert review %s: %w", review.ReviewID, err)
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("failed to commit reviews: %w", err)
+	}
+	return nil
+}
+
+// SaveBookData upserts the book's metadata into the books table of the
+// database at outputPath. Reviews are stored by SaveReviews.
+func (s *SQLiteStorage) SaveBookData(bookData models.BookData, outputPath string) error {
+	m := bookData.Metadata
+	if m.WorkID == "" {
+		return fmt.Errorf("book %s has no work ID", m.URL)
+	}
+
+	db, err := s.open(outputPath)
+	if err != nil {
+		return err
+	}
+
+	contributors := make([]jsonContributor, 0, len(m.Contributors))
+	for _, c := range m.Contributors {
+		contributors = append(contributors, jsonContributor{ID: c.ID, Name: c.Name, Role: c.Role, URL: c.URL})
+	}
+	contributorsJSON, err := json.Marshal(contributors)
+	if err != nil {
+		return fmt.Errorf("failed to encode contributors: %w", err)
+	}
+
+	genres := m.Genres
+	if genres == nil {
+		genres = []string{}
+	}
+	genresJSON, err := json.Marshal(genres)
+	if err != nil {
+		return fmt.Errorf("failed to encode genres: %w", err)
+	}
+
+	now := seenAt(time.Now())
+	var editionDate, firstPublished any
+	if !m.EditionDate.IsZero() {
+		editionDate = formatDate(m.EditionDate)
+	}
+	if !m.FirstPublished.IsZero() {
+		firstPublished = formatDate(m.FirstPublished)
+	}
+
+	_, err = db.Exec(upsertSQL("books", "work_id", bookColumns),
+		m.WorkID,
+		m.BookID,
+		m.URL,
+		m.Title,
+		m.Author,
+		string(contributorsJSON),
+		m.AverageRating,
+		m.RatingsCount,
+		m.TextReviewsCount,
+		m.RatingDistribution[0],
+		m.RatingDistribution[1],
+		m.RatingDistribution[2],
+		m.RatingDistribution[3],
+		m.RatingDistribution[4],
+		m.ISBN10,
+		m.ISBN13,
+		m.ASIN,
+		m.Language,
+		m.PageCount,
+		m.Format,
+		m.Publisher,
+		editionDate,
+		firstPublished,
+		string(genresJSON),
+		m.SeriesName,
+		m.SeriesPosition,
+		m.CoverURL,
+		m.Description,
+		s.opts.timeValue(bookData.ScrapedAt),
+		bookData.Fetched(),
+		now,
+		now,
+	)
+	if err != nil {
+		return fmt.Errorf("failed to upsert book %s: %w", m.WorkID, err)
+	}
+	return nil
+}
+
+// Close closes every database opened by the storage
+func (s *SQLiteStorage) Close() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	var firstErr error
+	for path, db := range s.dbs {
+		if err := db.Close(); err != nil && firstErr == nil {
+			firstErr = fmt.Errorf("failed to close %s: %w", path, err)
+		}
+		delete(s.dbs, path)
+	}
+	return firstErr
+}
+
+// seenAt renders the time for the first_seen_at and last_seen_at columns. It
+// is always RFC 3339 in UTC, whatever the output time format, so that rows
+// seen on the same day can still be ordered.
+func seenAt(t time.Time) string {
+	return t.UTC().Format(time.RFC3339)
+}
+
+// open returns the database at path, creating it and applying pending
+// migrations the first time it is used
+func (s *SQLiteStorage) open(path string) (*sql.DB, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if db, ok := s.dbs[path]; ok {
+		return db, nil
+	}
+
+	// Ensure directory exists
+	dir := filepath.Dir(path)
+	if dir != "." {
+		if err := os.MkdirAll(dir, 0755); err != nil {
+			return nil, fmt.Errorf("failed to create output directory: %w", err)
+		}
+	}
+
+	db, err := sql.Open("sqlite", path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open database: %w", err)
+	}
+	// SQLite allows a single writer; one connection avoids "database is locked" errors
+	db.SetMaxOpenConns(1)
+
+	if err := migrate(db); err != nil {
+		db.Close()
+		return nil, err
+	}
+
+	s.dbs[path] = db
+	return db, nil
+}
+
+// migrate applies the migrations the database has not seen yet
+func migrate(db *sql.DB) error {
+	var version int
+	if err := db.QueryRow("PRAGMA user_version").Scan(&version); err != nil {
+		return fmt.Errorf("failed to read schema version: %w", err)
+	}
+	if version > len(sqliteMigrations) {
+		return fmt.Errorf("database schema version %d is newer than supported version %d", version, len(sqliteMigrations))
+	}
+
+	for i := version; i < len(sqliteMigrations); i++ {
+		tx, err := db.Begin()
+		if err != nil {
+			return fmt.Errorf("failed to begin migration %d: %w", i+1, err)
+		}
+		if _, err := tx.Exec(sqliteMigrations[i]); err != nil {
+			tx.Rollback()
+			return fmt.Errorf("failed to apply migration %d: %w", i+1, err)
+		}
+		// PRAGMA does not accept bound parameters
+		if _, err := tx.Exec(fmt.Sprintf("PRAGMA user_version = %d", i+1)); err != nil {
+			tx.Rollback()
+			return fmt.Errorf("failed to record migration %d: %w", i+1, err)
+		}
+		if err := tx.Commit(); err != nil {
+			return fmt.Errorf("failed to commit migration %d: %w", i+1, err)
+		}
+	}
+	return nil
+}
+
+// upsertSQL builds an INSERT that updates every column except key and
+// first_seen_at when a row with the same key already exists. The statement
+// takes the column values followed by the first-seen and last-seen timestamps.
+func upsertSQL(table string, key string, columns []string) string {
+	updates := make([]string, 0, len(columns))
+	for _, column := range columns {
+		if column != key {
+			updates = append(updates, fmt.Sprintf("%s = excluded.%s", column, column))
+		}
+	}
+	updates = append(updates, "last_seen_at = excluded.last_seen_at")
+
+	placeholders := strings.TrimSuffix(strings.Repeat("?, ", len(columns)+2), ", ")
+
+	return fmt.Sprintf(
+		"INSERT INTO %s (%s, first_seen_at, last_seen_at) VALUES (%s) ON CONFLICT (%s) DO UPDATE SET %s",
+		table,
+		strings.Join(columns, ", "),
+		placeholders,
+		key,
+		strings.Join(updates, ", "),
+	)
+}
@@ -0,0 +1,214 @@
+package storage
+
+import (
+	"database/sql"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/rizkirmdhnnn/goodreadscrape/internal/models"
+)
+
+func TestSQLiteStorage_UpsertsReviews(t *testing.T) {
+	dbPath := filepath.Join(t.TempDir(), "out", "reviews.db")
+
+	s := NewSQLiteStorage(WithTimeFormat(TimeFormatRFC3339))
+	defer s.(*SQLiteStorage).Close()
+
+	five, three := 5, 3
+	reviews := []models.Review{
+		{
+			ReviewID:     "kca://review/1",
+			WorkID:       "kca://work/1",
+			ReviewerName: "John Doe",
+			Rating:       &five,
+			ReviewText:   "Great book!",
+			CreatedAt:    time.Date(2023, 1, 1, 12, 30, 0, 0, time.UTC),
+			Tags:         []models.ReviewTag{{Name: "favorites"}},
+		},
+		{
+			ReviewID:     "kca://review/2",
+			WorkID:       "kca://work/1",
+			ReviewerName: "Jane Doe",
+			ReviewText:   "Unrated",
+		},
+	}
+
+	if err := s.SaveReviews(reviews, dbPath); err != nil {
+		t.Fatalf("SaveReviews failed: %v", err)
+	}
+
+	// Re-scraping the same review updates it instead of adding a row
+	edited := reviews[0]
+	edited.Rating = &three
+	edited.ReviewText = "Changed my mind."
+	if err := s.SaveReviews([]models.Review{edited}, dbPath); err != nil {
+		t.Fatalf("SaveReviews failed: %v", err)
+	}
+
+	db := s.(*SQLiteStorage).dbs[dbPath]
+
+	var count int
+	if err := db.QueryRow("SELECT COUNT(*) FROM reviews").Scan(&count); err != nil {
+		t.Fatal(err)
+	}
+	if count != 2 {
+		t.Errorf("Expected 2 reviews, got %d", count)
+	}
+
+	var rating int
+	var text, createdAt, tags string
+	err := db.QueryRow("SELECT rating, review_text, created_at, tags FROM reviews WHERE review_id = ?", "kca://review/1").
+		Scan(&rating, &text, &createdAt, &tags)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if rating != 3 || text != "Changed my mind." {
+		t.Errorf("Expected updated review, got rating %d and text %q", rating, text)
+	}
+	if createdAt != "2023-01-01T12:30:00Z" {
+		t.Errorf("Expected created_at 2023-01-01T12:30:00Z, got %s", createdAt)
+	}
+	if tags != `[{"name":"favorites"}]` {
+		t.Errorf("Expected JSON tags, got %s", tags)
+	}
+
+	var unrated sql.NullInt64
+	if err := db.QueryRow("SELECT rating FROM reviews WHERE review_id = ?", "kca://review/2").Scan(&unrated); err != nil {
+		t.Fatal(err)
+	}
+	if unrated.Valid {
+		t.Errorf("Expected NULL rating, got %d", unrated.Int64)
+	}
+}
+
+func TestSQLiteStorage_KeepsFirstSeen(t *testing.T) {
+	dbPath := filepath.Join(t.TempDir(), "reviews.db")
+
+	// Bookkeeping times keep the time of day even with -time-format date
+	s := NewSQLiteStorage(WithTimeFormat(TimeFormatDate))
+	defer s.(*SQLiteStorage).Close()
+
+	bookData := models.BookData{
+		Metadata: models.BookMetadata{
+			WorkID: "kca://work/1",
+			Title:  "Laskar Pelangi",
+			URL:    "https://www.goodreads.com/book/show/1362193",
+		},
+	}
+	if err := s.SaveBookData(bookData, dbPath); err != nil {
+		t.Fatalf("SaveBookData failed: %v", err)
+	}
+
+	db := s.(*SQLiteStorage).dbs[dbPath]
+	// Backdate the row so the second save is distinguishable
+	if _, err := db.Exec("UPDATE books SET first_seen_at = '2000-01-01T00:00:00Z', last_seen_at = '2000-01-01T00:00:00Z'"); err != nil {
+		t.Fatal(err)
+	}
+
+	bookData.Metadata.RatingsCount = 42
+	bookData.Reviews = []models.Review{{ReviewID: "1"}}
+	if err := s.SaveBookData(bookData, dbPath); err != nil {
+		t.Fatalf("SaveBookData failed: %v", err)
+	}
+
+	var count, ratingsCount, reviewsFetched int
+	var firstSeen, lastSeen string
+	err := db.QueryRow("SELECT COUNT(*), ratings_count, reviews_fetched, first_seen_at, last_seen_at FROM books").
+		Scan(&count, &ratingsCount, &reviewsFetched, &firstSeen, &lastSeen)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if count != 1 {
+		t.Errorf("Expected 1 book, got %d", count)
+	}
+	if ratingsCount != 42 || reviewsFetched != 1 {
+		t.Errorf("Expected updated book, got ratings_count %d and reviews_fetched %d", ratingsCount, reviewsFetched)
+	}
+	if firstSeen != "2000-01-01T00:00:00Z" {
+		t.Errorf("Expected first_seen_at to be kept, got %s", firstSeen)
+	}
+	seen, err := time.Parse(time.RFC3339, lastSeen)
+	if err != nil || time.Since(seen) > time.Minute {
+		t.Errorf("Expected last_seen_at to be updated to an RFC 3339 time, got %s", lastSeen)
+	}
+}
+
+func TestSQLiteStorage_MigratesOnOpen(t *testing.T) {
+	dbPath := filepath.Join(t.TempDir(), "reviews.db")
+
+	s := NewSQLiteStorage()
+	if err := s.SaveReviews(nil, dbPath); err != nil {
+		t.Fatalf("SaveReviews failed: %v", err)
+	}
+	if err := s.(*SQLiteStorage).Close(); err != nil {
+		t.Fatalf("Close failed: %v", err)
+	}
+
+	// Reopening an up-to-date database must not re-run migrations
+	s = NewSQLiteStorage()
+	defer s.(*SQLiteStorage).Close()
+	if err := s.SaveReviews(nil, dbPath); err != nil {
+		t.Fatalf("SaveReviews on existing database failed: %v", err)
+	}
+
+	var version int
+	if err := s.(*SQLiteStorage).dbs[dbPath].QueryRow("PRAGMA user_version").Scan(&version); err != nil {
+		t.Fatal(err)
+	}
+	if version != len(sqliteMigrations) {
+		t.Errorf("Expected schema version %d, got %d", len(sqliteMigrations), version)
+	}
+}
+
+func TestSQLiteStorage_MigratesExistingDatabase(t *testing.T) {
+	dbPath := filepath.Join(t.TempDir(), "reviews.db")
+
+	// A database created before the review language columns were added
+	db, err := sql.Open("sqlite", dbPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := db.Exec(sqliteMigrations[0] + "; PRAGMA user_version = 1"); err != nil {
+		t.Fatal(err)
+	}
+	db.Close()
+
+	s := NewSQLiteStorage()
+	defer s.(*SQLiteStorage).Close()
+	review := models.Review{
+		ReviewID:           "kca://review/1",
+		WorkID:             "kca://work/1",
+		Language:           "en",
+		LanguageConfidence: 0.75,
+		FetchedLanguage:    "id",
+	}
+	if err := s.SaveReviews([]models.Review{review}, dbPath); err != nil {
+		t.Fatalf("SaveReviews failed: %v", err)
+	}
+
+	var language, fetched string
+	var confidence float64
+	err = s.(*SQLiteStorage).dbs[dbPath].QueryRow("SELECT language, language_confidence, fetched_language FROM reviews").
+		Scan(&language, &confidence, &fetched)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if language != "en" || confidence != 0.75 || fetched != "id" {
+		t.Errorf("Expected en (0.75) fetched as id, got %s (%v) fetched as %s", language, confidence, fetched)
+	}
+}
+
+func TestSQLiteStorage_RequiresKeys(t *testing.T) {
+	dbPath := filepath.Join(t.TempDir(), "reviews.db")
+
+	s := NewSQLiteStorage()
+	defer s.(*SQLiteStorage).Close()
+
+	if err := s.SaveReviews([]models.Review{{ReviewerName: "John Doe"}}, dbPath); err == nil {
+		t.Error("Expected error for review without ID")
+	}
+	if err := s.SaveBookData(models.BookData{}, dbPath); err == nil {
+		t.Error("Expected error for book without work ID")
+	}
+}
@@ -7,3 +7,26 @@ type Storage interface {
 	SaveReviews(reviews []models.Review, outputPath string) error
 	SaveBookData(bookData models.BookData, outputPath string) error
 }
+
+// BookResult is the outcome of scraping one book
+type BookResult struct {
+	URL    string // URL the book was scraped from
+	Book   models.BookMetadata
+	Status models.ScrapeStatus
+	Error  string
+}
+
+// StatusRecorder is implemented by backends that track the outcome of every
+// book, such as partitioned outputs. RecordStatus is called once per book,
+// after its book data and reviews were saved.
+type StatusRecorder interface {
+	RecordStatus(result BookResult, outputPath string) error
+}
+
+// BookRememberer is implemented by backends that append a row to the books
+// file on every SaveBookData. RememberBook registers a book whose row an
+// earlier run already wrote, as for a resumed book, so that its reviews are
+// saved as after SaveBookData without a second row.
+type BookRememberer interface {
+	RememberBook(bookData models.BookData, outputPath string)
+}
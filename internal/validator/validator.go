@@ -5,15 +5,19 @@ import (
 	"strings"
 )
 
-// ValidateGoodreadsURL checks if the provided URL is a valid Goodreads book URL
-func ValidateGoodreadsURL(rawURL string) bool {
+// defaultHost is the Goodreads host accepted regardless of configuration
+const defaultHost = "www.goodreads.com"
+
+// ValidateGoodreadsURL checks if the provided URL is a valid Goodreads book URL.
+// If baseURL is set, book URLs on its host are accepted as well.
+func ValidateGoodreadsURL(rawURL string, baseURL string) bool {
 	parsed, err := url.Parse(rawURL)
 	if err != nil {
 		return false
 	}
 
-	// Check if the hostname matches Goodreads
-	if parsed.Host != "www.goodreads.com" {
+	// Check if the hostname matches Goodreads or the configured base URL
+	if parsed.Host != defaultHost && !matchesHost(parsed.Host, baseURL) {
 		return false
 	}
 
@@ -21,3 +25,14 @@ func ValidateGoodreadsURL(rawURL string) bool {
 	return strings.Contains(parsed.Path, "/book/show/")
 }
 
+// matchesHost reports whether host equals the host of baseURL
+func matchesHost(host string, baseURL string) bool {
+	if baseURL == "" || host == "" {
+		return false
+	}
+	base, err := url.Parse(baseURL)
+	if err != nil {
+		return false
+	}
+	return strings.EqualFold(base.Host, host)
+}
@@ -6,6 +6,7 @@ func TestValidateGoodreadsURL(t *testing.T) {
 	tests := []struct {
 		name     string
 		url      string
+		baseURL  string
 		expected bool
 	}{
 		{
@@ -38,11 +39,28 @@ func TestValidateGoodreadsURL(t *testing.T) {
 			url:      "https://www.goodreads.com/book/show/54321?ref=nav_som",
 			expected: true,
 		},
+		{
+			name:     "Configured host",
+			url:      "http://127.0.0.1:8080/book/show/12345",
+			baseURL:  "http://127.0.0.1:8080",
+			expected: true,
+		},
+		{
+			name:     "Goodreads host with configured base URL",
+			url:      "https://www.goodreads.com/book/show/12345",
+			baseURL:  "http://127.0.0.1:8080",
+			expected: true,
+		},
+		{
+			name:     "Unconfigured local host",
+			url:      "http://127.0.0.1:8080/book/show/12345",
+			expected: false,
+		},
 	}
 
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			result := ValidateGoodreadsURL(tt.url)
+			result := ValidateGoodreadsURL(tt.url, tt.baseURL)
 			if result != tt.expected {
 				t.Errorf("ValidateGoodreadsURL(%q) = %v; want %v", tt.url, result, tt.expected)
 			}